@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+	"wikiholidays/wiki"
+)
+
+// DayDiff is the list of the changes of a day between two snapshots
+type DayDiff struct {
+	Month   string        `json:"month"`
+	Day     string        `json:"day"`
+	Changes []wiki.Change `json:"changes"`
+}
+
+func diffHolidays(oldHolidays, newHolidays Holidays) []DayDiff {
+	var diffs []DayDiff
+	for m := time.January; m <= time.December; m++ {
+		for day := 1; day <= monthDays[m-1]; day++ {
+			// a day missing in a snapshot is compared as an empty one
+			oldReport, newReport := wiki.Report{}, wiki.Report{}
+			if h, ok := oldHolidays[m][day]; ok {
+				oldReport = h.Report
+			}
+			if h, ok := newHolidays[m][day]; ok {
+				newReport = h.Report
+			}
+			if changes := wiki.DiffReports(&oldReport, &newReport); len(changes) > 0 {
+				diffs = append(diffs, DayDiff{m.String(), strconv.Itoa(day), changes})
+			}
+		}
+	}
+	return diffs
+}
+
+// runDiff shows the changes between two snapshots: wikiholidays diff holidays.v1.15.json holidays.v1.16.json
+func runDiff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		log.Fatal("usage: diff [-format text|json] old.json new.json")
+	}
+	if *format != "text" && *format != "json" {
+		log.Fatal("unknown format: ", *format)
+	}
+
+	oldHolidays, err := loadHolidays(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	newHolidays, err := loadHolidays(flags.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	diffs := diffHolidays(oldHolidays, newHolidays)
+
+	if *format == "json" {
+		repJ, err := json.MarshalIndent(diffs, "", " ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(append(repJ, '\n'))
+		return
+	}
+	changes := 0
+	for _, diff := range diffs {
+		month, _ := parseMonth(diff.Month)
+		day, _ := strconv.Atoi(diff.Day)
+		fmt.Println(dayTitle(month, day))
+		for _, change := range diff.Changes {
+			fmt.Println("  " + change.String())
+		}
+		changes += len(diff.Changes)
+	}
+	fmt.Printf("Days: %d, changes: %d\n", len(diffs), changes)
+}
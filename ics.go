@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"wikiholidays/wiki"
+)
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// runIcs exports a snapshot to an iCalendar feed: wikiholidays ics -in holidays.v1.16.json -categories professional
+func runIcs(args []string) {
+	flags := flag.NewFlagSet("ics", flag.ExitOnError)
+	input := flags.String("in", "holidays.v1.16.json", "snapshot to export")
+	output := flags.String("out", "holidays.ics", "calendar file to write")
+	name := flags.String("name", "Праздники", "calendar name")
+	categories := flags.String("categories", "", "comma separated categories: international, national, professional, religious")
+	confessions := flags.String("confessions", "", "comma separated confessions of the religious holidays, e.g. orthodox, catholic")
+	flags.Parse(args)
+
+	holidays, err := loadHolidays(*input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	filter := wiki.CalendarFilter{}
+	for _, category := range splitList(*categories) {
+		filter.Categories = append(filter.Categories, wiki.HolidayCategory(category))
+	}
+	for _, confession := range splitList(*confessions) {
+		filter.Confessions = append(filter.Confessions, wiki.Confession(confession))
+	}
+
+	var events []wiki.CalendarEvent
+	for m := time.January; m <= time.December; m++ {
+		for day := 1; day <= monthDays[m-1]; day++ {
+			if h, ok := holidays[m][day]; ok {
+				events = append(events, h.Report.CalendarEvents(m, day, filter)...)
+			}
+		}
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Print(err)
+		}
+	}()
+	if err := wiki.WriteICalendar(file, *name, events, time.Now()); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Events: %d, filename=%s", len(events), file.Name())
+}
@@ -2,69 +2,19 @@ package main
 
 import (
 	"encoding/json"
-	"io/ioutil"
+	"flag"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 	"wikiholidays/wiki"
 )
 
-type Response struct {
-	Batchcomplete string `json:"batchcomplete"`
-	Query         Query  `json:"query"`
-}
-
-type Query struct {
-	Pages map[string]Pages `json:"pages"`
-}
-
-type Pages struct {
-	Title   string `json:"title"`
-	Extract string `json:"extract"`
-	PageId  uint64 `json:"pageid"`
-	NS      uint64 `json:"ns"`
-}
-
-func getWikiReport(day string) string {
-	wikiRequest := "https://ru.wikipedia.org/w/api.php?" +
-		"action=query&format=json&&prop=extracts&exlimit=1&explaintext&titles=" + url.QueryEscape(day)
-
-	//log.Print(wikiRequest)
-	response, err := http.Get(wikiRequest)
-	if err != nil {
-		log.Print("Wikipedia is not respond", err)
-		return ""
-	}
-	defer func() {
-		if err := response.Body.Close(); err != nil {
-			log.Print(err)
-		}
-	}()
-	contents, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		log.Print(err)
-		return ""
-	}
-	var wr Response
-	if err := json.Unmarshal(contents, &wr); err != nil {
-		log.Print("Error", err)
-		return ""
-	}
-	if l := len(wr.Query.Pages); l == 0 || l > 1 {
-		log.Print("There must be only one page - ", l)
-		return ""
-	}
-	var content string
-	for _, v := range wr.Query.Pages {
-		content = v.Extract
-	}
-	return content
-}
-
 var monthsGenetive = [...]string{
 	"января",
 	"февраля",
@@ -98,12 +48,25 @@ type DayHolidays struct {
 	Month  string      `json:"month"`
 	Day    string      `json:"day"`
 	Report wiki.Report `json:"report"`
+	// revision of the article the report was parsed from, 0 in the snapshots before v1.17
+	Revision uint64 `json:"revision,omitempty"`
+	// version of the parser the report was made with, empty in the files before it was kept
+	Parser string `json:"parser,omitempty"`
 }
 
 type TypedDayHolidays struct {
-	Month  time.Month
-	Day    int
-	Report wiki.Report
+	Month    time.Month
+	Day      int
+	Report   wiki.Report
+	Revision uint64
+	Err      error
+}
+
+// DayFailure is a day which could not be loaded after all the retries
+type DayFailure struct {
+	Month string `json:"month"`
+	Day   string `json:"day"`
+	Error string `json:"error"`
 }
 
 type Job struct {
@@ -115,83 +78,288 @@ type Job struct {
 type MonthHolidays map[int]*DayHolidays
 type Holidays map[time.Month]MonthHolidays
 
-func loader(job chan *Job, wg *sync.WaitGroup) {
+// loadDay fetches and parses the page of the day, the fetch is retried with exponential backoff
+func loadDay(source wiki.PageSource, date string, retries int, backoff time.Duration) (wiki.Page, wiki.ParseResult, error) {
+	page, err := source.GetPage(date)
+	for attempt := 0; err != nil && attempt < retries; attempt++ {
+		delay := backoff << uint(attempt)
+		log.Print(date, ": ", err, ", retry in ", delay)
+		time.Sleep(delay)
+		page, err = source.GetPage(date)
+	}
+	if err != nil {
+		return page, wiki.ParseResult{}, err
+	}
+	// parse errors do not go away on retry
+	result, err := wiki.ParseWithWarnings(page.Extract)
+	return page, result, err
+}
 
-	for j := range job {
-		date := strconv.Itoa(j.Day) + " " + monthsGenetive[j.Month-1]
+func loader(source wiki.PageSource, opts *options, job chan *Job, wg *sync.WaitGroup) {
 
-		resp := getWikiReport(date)
-		if resp == "" {
-			log.Print(date)
+	for j := range job {
+		date := dayTitle(j.Month, j.Day)
 
-			return
-		}
-		report, err := wiki.Parse(resp)
+		page, result, err := loadDay(source, date, opts.retries, opts.backoff)
 		if err != nil {
-			log.Print("Error:", err)
-			wg.Done()
-			return
+			log.Print(date, ": ", err)
+		}
+		for _, warning := range result.Warnings {
+			log.Print(date, ": ", warning.String())
 		}
 
-		d := TypedDayHolidays{j.Month, j.Day, report}
+		d := TypedDayHolidays{j.Month, j.Day, result.Report, page.RevisionId, err}
 		j.resp <- &d
 		wg.Done()
 	}
 }
 
+// options of the batch loader
+type options struct {
+	output     string
+	workers    int
+	month      int
+	day        int
+	format     string
+	apiUrl     string
+	pretty     bool
+	retries    int
+	backoff    time.Duration
+	previous   string
+	checkpoint string
+}
+
+func parseOptions(args []string) options {
+	opts := options{}
+	flags := flag.NewFlagSet("wikiholidays", flag.ExitOnError)
+	flags.StringVar(&opts.output, "out", "holidays.v"+wiki.ParserVersion+".json", "snapshot file to write")
+	flags.IntVar(&opts.workers, "workers", 20, "number of the pages loaded in parallel")
+	flags.IntVar(&opts.month, "month", 0, "load only the month 1-12, the whole year by default")
+	flags.IntVar(&opts.day, "day", 0, "load only the day of the month, requires -month")
+	flags.StringVar(&opts.format, "format", "json", "output format: json (snapshot with the metadata) or jsonl (one day per line)")
+	flags.StringVar(&opts.apiUrl, "api", wiki.DefaultApiUrl, "MediaWiki API endpoint")
+	flags.BoolVar(&opts.pretty, "pretty", true, "indent the json output, -pretty=false writes it compact")
+	flags.IntVar(&opts.retries, "retries", 4, "number of the retries of a failed page")
+	flags.DurationVar(&opts.backoff, "backoff", time.Second, "delay before the first retry, doubled on every next one")
+	flags.StringVar(&opts.previous, "previous", "", "previous snapshot, the days with unchanged revisions are taken from it")
+	flags.StringVar(&opts.checkpoint, "checkpoint", "", "file of the loaded days to resume an interrupted run, <out>.checkpoint.jsonl by default")
+	flags.Parse(args)
+
+	if opts.workers < 1 {
+		log.Fatal("-workers must be positive")
+	}
+	if opts.retries < 0 {
+		log.Fatal("-retries must not be negative")
+	}
+	if opts.month < 0 || opts.month > 12 {
+		log.Fatal("-month must be in 1-12")
+	}
+	if opts.day != 0 {
+		if opts.month == 0 {
+			log.Fatal("-day requires -month")
+		}
+		if opts.day < 0 || opts.day > monthDays[opts.month-1] {
+			log.Fatal("-day is out of the month")
+		}
+	}
+	if opts.format != "json" && opts.format != "jsonl" {
+		log.Fatal("unknown format: ", opts.format)
+	}
+	if opts.checkpoint == "" {
+		opts.checkpoint = checkpointPath(opts.output)
+	}
+	return opts
+}
+
+// dates lists the days selected by the options in the calendar order
+func (opts *options) dates() []Job {
+	var dates []Job
+	for m := time.January; m <= time.December; m++ {
+		if opts.month != 0 && m != time.Month(opts.month) {
+			continue
+		}
+		for day := 1; day <= monthDays[m-1]; day++ {
+			if opts.day != 0 && day != opts.day {
+				continue
+			}
+			dates = append(dates, Job{Month: m, Day: day})
+		}
+	}
+	return dates
+}
+
+func writeHolidays(file *os.File, reports Holidays, opts *options) error {
+	if opts.format == "jsonl" {
+		encoder := json.NewEncoder(file)
+		for _, date := range opts.dates() {
+			if h, ok := reports[date.Month][date.Day]; ok {
+				if err := encoder.Encode(h); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	snapshot := wiki.NewSnapshot(opts.apiUrl)
+	for _, date := range opts.dates() {
+		if h, ok := reports[date.Month][date.Day]; ok {
+			snapshot.Add(date.Month, date.Day, h.Revision, h.Report)
+		}
+	}
+	return snapshot.Write(file, opts.pretty)
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "ics":
+			runIcs(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		}
+	}
+	opts := parseOptions(os.Args[1:])
+
 	log.Println("Load Data from Wiki")
 	var done = make(chan bool)
 
-	var jobsNum = 20
-	var jobs = make(chan *Job, jobsNum)
+	var jobs = make(chan *Job, opts.workers)
 
 	var reports = Holidays{}
+	var failed []*TypedDayHolidays
 	var days = make(chan *TypedDayHolidays)
 	var wg sync.WaitGroup
+	var source = &wiki.HttpSource{ApiUrl: opts.apiUrl, Client: http.DefaultClient}
+
+	resumed, err := loadCheckpoint(opts.checkpoint)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the days of the checkpoint win over the ones of the previous snapshot,
+	// both are taken only while the article and the parser are the same
+	known := resumed
+	if opts.previous != "" {
+		previous, err := loadHolidays(opts.previous)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for month, entries := range previous {
+			for day, h := range entries {
+				if _, ok := known[month][day]; !ok {
+					if err := known.add(h); err != nil {
+						log.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+	for _, date := range opts.dates() {
+		if _, ok := reports[date.Month]; !ok {
+			reports[date.Month] = MonthHolidays{}
+		}
+	}
+	pending, reused := reuseUnchanged(source, known, opts.dates())
+	for _, h := range reused {
+		if err := reports.add(h); err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.Printf("Unchanged: %d days, checkpoint=%s, previous=%s", len(reused), opts.checkpoint, opts.previous)
+
+	checkpoint, err := openCheckpoint(opts.checkpoint)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	for j := 0; j < jobsNum; j++ {
-		go loader(jobs, &wg)
+	for j := 0; j < opts.workers; j++ {
+		go loader(source, &opts, jobs, &wg)
 	}
 
 	go func() {
 		for d := range days {
-			h := DayHolidays{d.Month.String(), strconv.Itoa(d.Day), d.Report}
+			if d.Err != nil {
+				failed = append(failed, d)
+				continue
+			}
+			h := DayHolidays{d.Month.String(), strconv.Itoa(d.Day), d.Report, d.Revision, wiki.ParserVersion}
 			reports[d.Month][d.Day] = &h
+			if err := checkpoint.add(&h); err != nil {
+				log.Print(err)
+			}
 		}
 		done <- true
 	}()
 
-	for m := time.January; m <= time.December; m++ {
-		month := MonthHolidays{}
-		reports[m] = month
-		for day := 1; day <= monthDays[m-1]; day++ {
-			wg.Add(1)
-			jobs <- &Job{m, day, days}
-		}
+	log.Printf("Fetch: %d days", len(pending))
+	for _, date := range pending {
+		wg.Add(1)
+		jobs <- &Job{date.Month, date.Day, days}
 	}
 
 	wg.Wait()
 	close(days)
 	log.Println("Wait last results")
 	<-done
-	tmpFile, err := os.OpenFile("holidays.v1.17.json", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
-
-	if err != nil {
+	if err := checkpoint.Close(); err != nil {
+		log.Print(err)
+	}
+	if err := writeFile(opts.output, func(file *os.File) error {
+		return writeHolidays(file, reports, &opts)
+	}); err != nil {
 		log.Fatal(err)
 	}
-	defer func() {
-		if err := tmpFile.Close(); err != nil {
-			log.Print(err)
-		}
-	}()
-	repJ, err := json.MarshalIndent(reports, "", " ")
+	log.Printf("Len: %d, filename=%s", len(reports), opts.output)
 
-	if err != nil {
-		log.Fatal(err)
+	failuresFile := failuresPath(opts.output)
+	if len(failed) == 0 {
+		// the list of the previous run must not outlive the fixed days,
+		// the checkpoint is kept only to retry the failed days
+		for _, path := range []string{failuresFile, opts.checkpoint} {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				log.Print(err)
+			}
+		}
+		return
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[i].Month < failed[j].Month || failed[i].Month == failed[j].Month && failed[i].Day < failed[j].Day
+	})
+	var failures []DayFailure
+	for _, d := range failed {
+		failures = append(failures, DayFailure{d.Month.String(), strconv.Itoa(d.Day), d.Err.Error()})
+	}
+	if err := writeFile(failuresFile, func(file *os.File) error {
+		repJ, err := json.MarshalIndent(failures, "", " ")
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(repJ)
+		return err
+	}); err != nil {
+		log.Print(err)
 	}
+	for _, failure := range failures {
+		log.Print("Missing ", failure.Day, " ", failure.Month, ": ", failure.Error)
+	}
+	log.Printf("Failed: %d of %d days, filename=%s", len(failures), len(opts.dates()), failuresFile)
+	os.Exit(1)
+}
 
-	tmpFile.Write(repJ)
+// failuresPath is the file of the failure list next to the snapshot: holidays.v1.17.json -> holidays.v1.17.failures.json
+func failuresPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".failures.json"
+}
 
-	log.Printf("Len: %d, filename=%s", len(reports), tmpFile.Name())
+func writeFile(path string, write func(file *os.File) error) error {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
@@ -0,0 +1,101 @@
+// Package movable computes the dates of the feasts which depend on the date of Easter
+package movable
+
+import "time"
+
+// Church is the tradition of the computus
+type Church string
+
+const (
+	// the Julian computus of the Orthodox churches
+	Orthodox Church = "orthodox"
+	// the Gregorian computus of the Catholic and Protestant churches
+	Western Church = "western"
+)
+
+// OrthodoxEaster returns the date of the Orthodox Easter in the Gregorian calendar
+func OrthodoxEaster(year int) time.Time {
+	a, b, c := year%4, year%7, year%19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	month := (d + e + 114) / 31
+	day := (d+e+114)%31 + 1
+	// the Julian calendar falls behind by the century years which are not leap in the Gregorian one,
+	// Easter is always after February so the difference of the year is used
+	lag := year/100 - year/400 - 2
+	return time.Date(year, time.Month(month), day+lag, 0, 0, 0, 0, time.UTC)
+}
+
+// WesternEaster returns the date of the Catholic and Protestant Easter
+func WesternEaster(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// Easter returns the date of Easter of the church
+func Easter(church Church, year int) time.Time {
+	if church == Orthodox {
+		return OrthodoxEaster(year)
+	}
+	return WesternEaster(year)
+}
+
+// Feast is a feast celebrated on the day at the offset from Easter
+type Feast struct {
+	Title  string
+	Church Church
+	// days from Easter, negative before it
+	Offset int
+	// length of the feast in days, 0 is the same as 1
+	Days int
+}
+
+// Feasts are the movable feasts in the order of the year
+var Feasts = []Feast{
+	{Title: "Масленица", Church: Orthodox, Offset: -55, Days: 6},
+	{Title: "Прощёное воскресенье, последний день Масленицы", Church: Orthodox, Offset: -49},
+	{Title: "Жирный вторник", Church: Western, Offset: -47},
+	{Title: "Пепельная среда, начало Великого поста", Church: Western, Offset: -46},
+	{Title: "Вход Господень в Иерусалим (Вербное воскресенье)", Church: Orthodox, Offset: -7},
+	{Title: "Пальмовое воскресенье", Church: Western, Offset: -7},
+	{Title: "Пасха (Светлое Христово Воскресение)", Church: Orthodox},
+	{Title: "Пасха (Воскресение Христово)", Church: Western},
+	{Title: "Вознесение Господне", Church: Orthodox, Offset: 39},
+	{Title: "Вознесение Господне", Church: Western, Offset: 39},
+	{Title: "День Святой Троицы (Пятидесятница)", Church: Orthodox, Offset: 49},
+	{Title: "Пятидесятница (Сошествие Святого Духа)", Church: Western, Offset: 49},
+	{Title: "Праздник Святой Троицы", Church: Western, Offset: 56},
+}
+
+// Date returns the first day of the feast in the year
+func (feast *Feast) Date(year int) time.Time {
+	return Easter(feast.Church, year).AddDate(0, 0, feast.Offset)
+}
+
+// On lists the feasts celebrated on the day
+func On(day time.Time) []Feast {
+	year, month, dayNum := day.Date()
+	date := time.Date(year, month, dayNum, 0, 0, 0, 0, time.UTC)
+	var feasts []Feast
+	for _, feast := range Feasts {
+		first := feast.Date(year)
+		days := feast.Days
+		if days < 1 {
+			days = 1
+		}
+		if !date.Before(first) && date.Before(first.AddDate(0, 0, days)) {
+			feasts = append(feasts, feast)
+		}
+	}
+	return feasts
+}
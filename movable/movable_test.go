@@ -0,0 +1,68 @@
+package movable
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEaster(t *testing.T) {
+	for _, test := range []struct {
+		year     int
+		orthodox time.Time
+		western  time.Time
+	}{
+		{1900, date(1900, time.April, 22), date(1900, time.April, 15)},
+		{2000, date(2000, time.April, 30), date(2000, time.April, 23)},
+		{2019, date(2019, time.April, 28), date(2019, time.April, 21)},
+		{2024, date(2024, time.May, 5), date(2024, time.March, 31)},
+		{2025, date(2025, time.April, 20), date(2025, time.April, 20)},
+		{2026, date(2026, time.April, 12), date(2026, time.April, 5)},
+		{2100, date(2100, time.May, 2), date(2100, time.March, 28)},
+	} {
+		if easter := OrthodoxEaster(test.year); !easter.Equal(test.orthodox) {
+			t.Error("Unexpected Orthodox Easter of", test.year, easter)
+		}
+		if easter := WesternEaster(test.year); !easter.Equal(test.western) {
+			t.Error("Unexpected Western Easter of", test.year, easter)
+		}
+	}
+}
+
+func titles(feasts []Feast) []string {
+	var titles []string
+	for _, feast := range feasts {
+		titles = append(titles, feast.Title)
+	}
+	return titles
+}
+
+func TestOn(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	for _, test := range []struct {
+		day    time.Time
+		titles []string
+	}{
+		{time.Date(2025, time.April, 20, 23, 30, 0, 0, moscow), []string{"Пасха (Светлое Христово Воскресение)", "Пасха (Воскресение Христово)"}},
+		{date(2026, time.February, 16), []string{"Масленица"}},
+		{date(2026, time.February, 21), []string{"Масленица"}},
+		{date(2026, time.February, 22), []string{"Прощёное воскресенье, последний день Масленицы"}},
+		{date(2026, time.May, 31), []string{"День Святой Троицы (Пятидесятница)", "Праздник Святой Троицы"}},
+		{date(2026, time.May, 21), []string{"Вознесение Господне"}},
+		{date(2026, time.May, 22), nil},
+	} {
+		got := titles(On(test.day))
+		if len(got) != len(test.titles) {
+			t.Error("Unexpected feasts of", test.day, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.titles[i] {
+				t.Error("Unexpected feasts of", test.day, got)
+			}
+		}
+	}
+}
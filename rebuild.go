@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"wikiholidays/wiki"
+)
+
+// dayTitle is the title of the article of the day, e.g. "1 января"
+func dayTitle(month time.Month, day int) string {
+	return strconv.Itoa(day) + " " + monthsGenetive[month-1]
+}
+
+// parseMonth reverses time.Month.String() used for the month of DayHolidays
+func parseMonth(name string) (time.Month, error) {
+	for m := time.January; m <= time.December; m++ {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+	return 0, errors.New("unknown month: " + name)
+}
+
+func (holidays Holidays) add(h *DayHolidays) error {
+	month, err := parseMonth(h.Month)
+	if err != nil {
+		return err
+	}
+	day, err := strconv.Atoi(h.Day)
+	if err != nil {
+		return err
+	}
+	if _, ok := holidays[month]; !ok {
+		holidays[month] = MonthHolidays{}
+	}
+	holidays[month][day] = h
+	return nil
+}
+
+// readDays reads the days written one per line, a broken line (e.g. the last one of an interrupted run) is skipped
+func readDays(reader io.Reader) (Holidays, error) {
+	holidays := Holidays{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		h := DayHolidays{}
+		if err := json.Unmarshal(scanner.Bytes(), &h); err != nil {
+			log.Print("line ", lineNum, ": ", err)
+			continue
+		}
+		if err := holidays.add(&h); err != nil {
+			log.Print("line ", lineNum, ": ", err)
+		}
+	}
+	return holidays, scanner.Err()
+}
+
+// loadHolidays reads a snapshot written in any of the output formats
+func loadHolidays(path string) (Holidays, error) {
+	if filepath.Ext(path) == ".jsonl" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return readDays(file)
+	}
+	snapshot, err := wiki.LoadSnapshotFile(path)
+	if err != nil {
+		return nil, err
+	}
+	holidays := Holidays{}
+	for _, d := range snapshot.Days {
+		if _, ok := holidays[d.Month]; !ok {
+			holidays[d.Month] = MonthHolidays{}
+		}
+		holidays[d.Month][d.Day] = &DayHolidays{d.Month.String(), strconv.Itoa(d.Day), d.Report, d.Revision, snapshot.Parser}
+	}
+	return holidays, nil
+}
+
+// checkpointPath is the file of the loaded days next to the snapshot: holidays.v1.17.json -> holidays.v1.17.checkpoint.jsonl
+func checkpointPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".checkpoint.jsonl"
+}
+
+func loadCheckpoint(path string) (Holidays, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Holidays{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readDays(file)
+}
+
+// checkpoint appends every loaded day to the file so the next run can skip it
+type checkpoint struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+func openCheckpoint(path string) (*checkpoint, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint{file, json.NewEncoder(file)}, nil
+}
+
+func (checkpoint *checkpoint) add(h *DayHolidays) error {
+	return checkpoint.encoder.Encode(h)
+}
+
+func (checkpoint *checkpoint) Close() error {
+	return checkpoint.file.Close()
+}
+
+// reuseUnchanged takes the known days whose articles have the same revision and which were parsed
+// by the same version of the parser, all the days are left pending when the source can not report the revisions
+func reuseUnchanged(source wiki.PageSource, known Holidays, dates []Job) (pending []Job, reused []*DayHolidays) {
+	revisionSource, ok := source.(wiki.RevisionSource)
+	if !ok {
+		return dates, nil
+	}
+	var titles []string
+	for _, date := range dates {
+		titles = append(titles, dayTitle(date.Month, date.Day))
+	}
+	revisions, err := revisionSource.GetRevisions(titles)
+	if err != nil {
+		log.Print("revisions: ", err)
+		return dates, nil
+	}
+	for _, date := range dates {
+		h, ok := known[date.Month][date.Day]
+		if ok && h.Revision != 0 && h.Revision == revisions[dayTitle(date.Month, date.Day)] && h.Parser == wiki.ParserVersion {
+			reused = append(reused, h)
+		} else {
+			pending = append(pending, date)
+		}
+	}
+	return pending, reused
+}
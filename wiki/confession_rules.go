@@ -0,0 +1,80 @@
+// Code generated by gen_data.go from confession_rules.json; DO NOT EDIT.
+
+package wiki
+
+// the rules of the confessions, the headings of the "others" group were one regex, the group keeps the leftmost match of it
+const defaultConfessionRules = `{
+ "exclude": [
+  "^[Пп]амять .*",
+  ".*священномучени.*",
+  ".*мощей.*",
+  ".*преставление .*",
+  "Собор .*",
+  ".*переходящее празднование в.*",
+  "предпраздн.*"
+ ],
+ "rules": [
+  {
+   "id": "orthodox",
+   "pattern": "Православ(ие|ные):?( (\\(|.*)Русская Православная Церковь(\\)|.*))?( ?\\(старообрядцы\\))?|В .*[Пп]равосл.* церкв(и|ях):?|(\\(|.*)Русская Православная Церковь(\\)|.*)",
+   "abbr": "правосл."
+  },
+  {
+   "id": "catholic",
+   "pattern": "Католи(цизм|ческие|чество)|В [Кк]атолич.* церко?в(ь|и|ях):?|(В )?([^-]|^)[Кк]атолич.* церко?в(ь|и|ях):?|Католици́зм или католи́чество",
+   "abbr": "катол.",
+   "endsIconList": true
+  },
+  {
+   "id": "zoroastrianism",
+   "pattern": "Зороастризм",
+   "group": "others"
+  },
+  {
+   "id": "other",
+   "pattern": "Другие конфессии|В католичестве и протестантстве",
+   "group": "others"
+  },
+  {
+   "id": "slavic_pagan",
+   "pattern": ":?Славянские праздники:?",
+   "group": "others"
+  },
+  {
+   "id": "islam",
+   "pattern": "Ислам(ские|.?)",
+   "group": "others"
+  },
+  {
+   "id": "oriental_orthodox",
+   "pattern": "В Древневосточных церквях:?",
+   "group": "others"
+  },
+  {
+   "id": "buddhism",
+   "pattern": "Буддизм",
+   "group": "others"
+  },
+  {
+   "id": "bahai",
+   "pattern": "Бахаи(зм)?",
+   "abbr": "бахаи"
+  },
+  {
+   "id": "armenian",
+   "pattern": "Армянская апостольская церковь:?",
+   "abbr": "Армянская апостол. церковь"
+  },
+  {
+   "id": "lutheran",
+   "pattern": "Лютеранство:?",
+   "abbr": "лютер"
+  },
+  {
+   "id": "paganism",
+   "pattern": "Язычество:?",
+   "abbr": "языч."
+  }
+ ]
+}
+`
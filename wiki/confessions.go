@@ -0,0 +1,189 @@
+package wiki
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Confession is the canonical id of a confession, custom rules may introduce new ones
+type Confession string
+
+const (
+	ConfessionOrthodox         Confession = "orthodox"
+	ConfessionCatholic         Confession = "catholic"
+	ConfessionLutheran         Confession = "lutheran"
+	ConfessionArmenian         Confession = "armenian"
+	ConfessionOrientalOrthodox Confession = "oriental_orthodox"
+	ConfessionBahai            Confession = "bahai"
+	ConfessionIslam            Confession = "islam"
+	ConfessionBuddhism         Confession = "buddhism"
+	ConfessionZoroastrianism   Confession = "zoroastrianism"
+	ConfessionSlavicPagan      Confession = "slavic_pagan"
+	ConfessionPaganism         Confession = "paganism"
+	ConfessionOther            Confession = "other"
+)
+
+// ConfessionRule maps the heading of a confession in the "Религиозные" section to its group.
+// Rules are checked in order, the first matching one wins
+type ConfessionRule struct {
+	Id      Confession `json:"id"`
+	Pattern string     `json:"pattern"`
+	Abbr    string     `json:"abbr,omitempty"`
+	Exclude []string   `json:"exclude,omitempty"`
+	// the rules of the same group are matched as one pattern: the leftmost heading in the line wins
+	Group string `json:"group,omitempty"`
+	// the heading ends the list of the icons skipped after "празднование … икон …:"
+	EndsIconList bool `json:"endsIconList,omitempty"`
+
+	pattern *regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// ConfessionRules is the ordered table of confession rules,
+// Exclude lists the descriptions which are dropped whatever the confession is
+type ConfessionRules struct {
+	Exclude []string          `json:"exclude"`
+	Rules   []*ConfessionRule `json:"rules"`
+
+	exclude []*regexp.Regexp
+}
+
+var confessionRulesLock sync.RWMutex
+var confessionRules = DefaultConfessionRules()
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func (rules *ConfessionRules) compile() error {
+	var err error
+	if rules.exclude, err = compilePatterns(rules.Exclude); err != nil {
+		return err
+	}
+	for _, rule := range rules.Rules {
+		if rule.Id == "" || rule.Pattern == "" {
+			return errors.New("confession rule must have id and pattern")
+		}
+		if rule.pattern, err = regexp.Compile(rule.Pattern); err != nil {
+			return err
+		}
+		if rule.exclude, err = compilePatterns(rule.Exclude); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule which pattern is found in the line
+func (rules *ConfessionRules) Match(line string) *ConfessionRule {
+	for i, rule := range rules.Rules {
+		index := rule.pattern.FindStringIndex(line)
+		if index == nil {
+			continue
+		}
+		if rule.Group == "" {
+			return rule
+		}
+		matched := rule
+		for _, next := range rules.Rules[i+1:] {
+			if next.Group != rule.Group {
+				continue
+			}
+			if nextIndex := next.pattern.FindStringIndex(line); nextIndex != nil && nextIndex[0] < index[0] {
+				matched, index = next, nextIndex
+			}
+		}
+		return matched
+	}
+	return nil
+}
+
+// ByAbbr returns the confession of the abbreviation the reports were written with before the confessions were kept,
+// the headings of the "others" group have no abbreviation and give an empty confession
+func (rules *ConfessionRules) ByAbbr(abbr string) Confession {
+	if abbr == "" {
+		return ""
+	}
+	for _, rule := range rules.Rules {
+		if rule.Abbr == abbr {
+			return rule.Id
+		}
+	}
+	return ""
+}
+
+// Excluded checks the description against the common exclusions and the ones of the rule
+func (rules *ConfessionRules) Excluded(rule *ConfessionRule, line string) bool {
+	for _, re := range rules.exclude {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	if rule != nil {
+		for _, re := range rule.exclude {
+			if re.MatchString(line) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func LoadConfessionRules(reader io.Reader) (*ConfessionRules, error) {
+	rules := ConfessionRules{}
+	if err := json.NewDecoder(reader).Decode(&rules); err != nil {
+		return nil, err
+	}
+	if err := rules.compile(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func LoadConfessionRulesFile(path string) (*ConfessionRules, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadConfessionRules(file)
+}
+
+// DefaultConfessionRules returns the rules of confession_rules.json built into the library,
+// the file is the one to copy and extend for LoadConfessionRules
+func DefaultConfessionRules() *ConfessionRules {
+	rules, err := LoadConfessionRules(strings.NewReader(defaultConfessionRules))
+	if err != nil {
+		panic(err)
+	}
+	return rules
+}
+
+// SetConfessionRules replaces the rules used by Parse, nil restores the embedded ones
+func SetConfessionRules(rules *ConfessionRules) {
+	if rules == nil {
+		rules = DefaultConfessionRules()
+	}
+	confessionRulesLock.Lock()
+	defer confessionRulesLock.Unlock()
+	confessionRules = rules
+}
+
+func getConfessionRules() *ConfessionRules {
+	confessionRulesLock.RLock()
+	defer confessionRulesLock.RUnlock()
+	return confessionRules
+}
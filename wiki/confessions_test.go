@@ -0,0 +1,174 @@
+package wiki
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfessionRules_Generated(t *testing.T) {
+	contents, err := ioutil.ReadFile("../confession_rules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != defaultConfessionRules {
+		t.Error("confession_rules.go is out of date, run go generate ./wiki")
+	}
+	ids := map[Confession]bool{}
+	for _, rule := range DefaultConfessionRules().Rules {
+		if ids[rule.Id] {
+			t.Error("Duplicate confession id:", rule.Id)
+		}
+		ids[rule.Id] = true
+	}
+}
+
+func TestConfessionRules_Confession(t *testing.T) {
+	custom, err := LoadConfessionRules(strings.NewReader(`{
+ "rules": [
+  {"id": "shinto", "pattern": "Синтоизм:?", "abbr": "синто"},
+  {"id": "lutheran", "pattern": "Лютеранство:?", "abbr": "лютер"}
+ ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name       string
+		rules      *ConfessionRules
+		line       string
+		confession Confession
+		abbr       string
+	}{
+		{"known", nil, " Лютеранство: День памяти", ConfessionLutheran, "лютер"},
+		{"unknown", nil, " Синтоизм: Сэцубун", ConfessionOther, ""},
+		{"override", custom, " Синтоизм: Сэцубун", "shinto", "синто"},
+		{"override without the default", custom, " В православной церквиВведение во храм", ConfessionOther, ""},
+	} {
+		SetConfessionRules(test.rules)
+		report, err := Parse("== Праздники и памятные дни ==\n\n=== Религиозные ===\n" + test.line + "\n")
+		SetConfessionRules(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		holidays := report.HolidaysRlg.Holidays
+		if len(holidays) != 1 {
+			t.Fatalf("%s: expected one holiday, actual %d", test.name, len(holidays))
+		}
+		if holidays[0].Confession != test.confession || holidays[0].GroupAbbr != test.abbr {
+			t.Errorf("%s: unexpected holiday %+v", test.name, *holidays[0])
+		}
+	}
+}
+
+func TestConfessionRules_ByAbbr(t *testing.T) {
+	rules := DefaultConfessionRules()
+	for abbr, confession := range map[string]Confession{
+		"правосл.": ConfessionOrthodox,
+		"катол.":   ConfessionCatholic,
+		"лютер":    ConfessionLutheran,
+		"Армянская апостол. церковь": ConfessionArmenian,
+		"":      "",
+		"синто": "",
+	} {
+		if actual := rules.ByAbbr(abbr); actual != confession {
+			t.Errorf("ByAbbr(%q): expected %q, actual %q", abbr, confession, actual)
+		}
+	}
+	holiday := ReligiousHolidayDescr{GroupAbbr: "катол.", Descriptions: []string{"Анно II"}}
+	if confession := holiday.confession(); confession != ConfessionCatholic {
+		t.Error("Unexpected confession of the v1 holiday:", confession)
+	}
+}
+
+func TestConfessionRules_Custom(t *testing.T) {
+	rules, err := LoadConfessionRules(strings.NewReader(`{
+ "exclude": ["^[Пп]амять .*"],
+ "rules": [
+  {"id": "judaism", "pattern": "Иудаизм:?", "abbr": "иуд."},
+  {"id": "orthodox", "pattern": "В православной церкви", "abbr": "правосл.", "exclude": ["Собор .*"]}
+ ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetConfessionRules(rules)
+	defer SetConfessionRules(nil)
+
+	fullReport := `== Праздники и памятные дни ==
+
+=== Религиозные ===
+ Иудаизм: Ту би-Шват
+ В православной церквипамять мученика Платона (302 или 306 год);
+Собор святых Эстонской земли;
+Введение во храм Пресвятой Богородицы.
+`
+	expected := `*Праздники и памятные дни*
+
+_Религиозные_
+- Ту би-Шват (иуд.)
+- Введение во храм Пресвятой Богородицы (правосл.)
+`
+	testParserByString(t, fullReport, expected)
+}
+
+func TestConfessionRules_Invalid(t *testing.T) {
+	if _, err := LoadConfessionRules(strings.NewReader(`{"rules": [{"id": "bad", "pattern": "("}]}`)); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+	if _, err := LoadConfessionRules(strings.NewReader(`{"rules": [{"pattern": "Ислам"}]}`)); err == nil {
+		t.Error("Expected error for rule without id")
+	}
+}
+
+func TestReligiousHolidays_Filter(t *testing.T) {
+	fullReport := `== Праздники и памятные дни ==
+
+=== Религиозные ===
+ В католической церквиАнно II
+ В православной церквиВведение во храм Пресвятой Богородицы
+ Лютеранство: День памяти Николауса Людвига фон Цинцендорфа
+`
+	report, err := Parse(fullReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orthodox := report.HolidaysRlg.Filter(ConfessionOrthodox)
+	if len(orthodox.Holidays) != 1 || orthodox.Holidays[0].Descriptions[0] != "Введение во храм Пресвятой Богородицы" {
+		t.Error("Unexpected orthodox holidays:", orthodox.Holidays)
+	}
+	western := report.HolidaysRlg.Filter(ConfessionCatholic, ConfessionLutheran)
+	if len(western.Holidays) != 2 || western.Holidays[0].Confession != ConfessionCatholic || western.Holidays[1].Confession != ConfessionLutheran {
+		t.Error("Unexpected western holidays:", western.Holidays)
+	}
+	if islam := report.HolidaysRlg.Filter(ConfessionIslam); !islam.Empty() {
+		t.Error("Unexpected islamic holidays:", islam.Holidays)
+	}
+}
+
+func TestReligiousHolidays_FilterByAbbr(t *testing.T) {
+	// the groups of the reports written before the confessions were kept
+	holidays := ReligiousHolidays{Holidays: []*ReligiousHolidayDescr{
+		{Descriptions: []string{"Введение во храм Пресвятой Богородицы"}, GroupAbbr: "правосл."},
+		{Descriptions: []string{"Анно II"}, GroupAbbr: "катол."},
+		{Descriptions: []string{"Ту би-Шват"}},
+	}}
+	orthodox := holidays.Filter(ConfessionOrthodox)
+	if len(orthodox.Holidays) != 1 || orthodox.Holidays[0].GroupAbbr != "правосл." {
+		t.Error("Unexpected orthodox holidays:", orthodox.Holidays)
+	}
+	expected := `<ul>
+<li>Анно II <abbr title="Католицизм">катол.</abbr></li>
+</ul>
+`
+	validateStrings(t, expected, NewHTMLRenderer().religious(&ReligiousHolidays{Holidays: holidays.Holidays[1:2]}))
+
+	report := Report{HolidaysRlg: holidays}
+	// Easter of 2024 by the Gregorian computus
+	day := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+	report.AddMovableFeasts(&day)
+	if len(report.HolidaysRlg.Holidays) != 3 || report.HolidaysRlg.Holidays[1].Descriptions[0] == "Анно II" {
+		t.Error("The feast must join the catholic group:", report.HolidaysRlg.Holidays[1])
+	}
+}
@@ -0,0 +1,342 @@
+package wiki
+
+import "strings"
+
+// countryCodes maps Russian names of countries, as they are written in the articles, to ISO 3166-1 alpha-2 codes
+var countryCodes = map[string]string{
+	"Австралия":            "AU",
+	"Австрия":              "AT",
+	"Азербайджан":          "AZ",
+	"Албания":              "AL",
+	"Алжир":                "DZ",
+	"Американское Самоа":   "AS",
+	"Ангола":               "AO",
+	"Андорра":              "AD",
+	"Антигуа":              "AG",
+	"Антигуа и Барбуда":    "AG",
+	"Аргентина":            "AR",
+	"Армения":              "AM",
+	"Аруба":                "AW",
+	"Афганистан":           "AF",
+	"Багамские Острова":    "BS",
+	"Багамы":               "BS",
+	"Бангладеш":            "BD",
+	"Барбадос":             "BB",
+	"Бахрейн":              "BH",
+	"Белиз":                "BZ",
+	"Белоруссия":           "BY",
+	"Беларусь":             "BY",
+	"Бельгия":              "BE",
+	"Бенин":                "BJ",
+	"Бермуды":              "BM",
+	"Болгария":             "BG",
+	"Боливия":              "BO",
+	"Босния и Герцеговина": "BA",
+	"Ботсвана":             "BW",
+	"Бразилия":             "BR",
+	"Бруней":               "BN",
+	"Буркина-Фасо":         "BF",
+	"Бурунди":              "BI",
+	"Бутан":                "BT",
+	"Вануату":              "VU",
+	"Ватикан":              "VA",
+	"Великобритания":       "GB",
+	"Венгрия":              "HU",
+	"Венесуэла":            "VE",
+	"Восточный Тимор":      "TL",
+	"Вьетнам":              "VN",
+	"Габон":                "GA",
+	"Гаити":                "HT",
+	"Гайана":               "GY",
+	"Гамбия":               "GM",
+	"Гана":                 "GH",
+	"Гватемала":            "GT",
+	"Гвинея":               "GN",
+	"Гвинея-Бисау":         "GW",
+	"Германия":             "DE",
+	"ФРГ":                  "DE",
+	"Гибралтар":            "GI",
+	"Гондурас":             "HN",
+	"Гонконг":              "HK",
+	"Гренада":              "GD",
+	"Гренландия":           "GL",
+	"Греция":               "GR",
+	"Грузия":               "GE",
+	"Гуам":                 "GU",
+	"Дания":                "DK",
+	"Джерси":               "JE",
+	"Джибути":              "DJ",
+	"Доминика":             "DM",
+	"Доминиканская Республика": "DO",
+	"Египет":     "EG",
+	"Замбия":     "ZM",
+	"Зимбабве":   "ZW",
+	"Израиль":    "IL",
+	"Индия":      "IN",
+	"Индонезия":  "ID",
+	"Иордания":   "JO",
+	"Ирак":       "IQ",
+	"Иран":       "IR",
+	"Ирландия":   "IE",
+	"Исландия":   "IS",
+	"Испания":    "ES",
+	"Италия":     "IT",
+	"Йемен":      "YE",
+	"Кабо-Верде": "CV",
+	"Казахстан":  "KZ",
+	"Камбоджа":   "KH",
+	"Камерун":    "CM",
+	"Канада":     "CA",
+	"Катар":      "QA",
+	"Кения":      "KE",
+	"Кипр":       "CY",
+	"Киргизия":   "KG",
+	"Кыргызстан": "KG",
+	"Кирибати":   "KI",
+	"Китай":      "CN",
+	"КНР":        "CN",
+	"Китайская Республика":           "TW",
+	"Китайская Республика (Тайвань)": "TW",
+	"Тайвань":          "TW",
+	"КНДР":             "KP",
+	"Колумбия":         "CO",
+	"Коморы":           "KM",
+	"Республика Конго": "CG",
+	"Демократическая Республика Конго": "CD",
+	"ДР Конго":          "CD",
+	"Республика Косово": "XK",
+	"Косово":            "XK",
+	"Коста-Рика":        "CR",
+	"Кот-д’Ивуар":       "CI",
+	"Куба":              "CU",
+	"Кувейт":            "KW",
+	"Кюрасао":           "CW",
+	"Лаос":              "LA",
+	"Латвия":            "LV",
+	"Лесото":            "LS",
+	"Либерия":           "LR",
+	"Ливан":             "LB",
+	"Ливия":             "LY",
+	"Ливийская Арабская Джамахирия": "LY",
+	"Литва":              "LT",
+	"Лихтенштейн":        "LI",
+	"Люксембург":         "LU",
+	"Маврикий":           "MU",
+	"Мавритания":         "MR",
+	"Мадагаскар":         "MG",
+	"Макао":              "MO",
+	"Малави":             "MW",
+	"Малайзия":           "MY",
+	"Мали":               "ML",
+	"Мальдивы":           "MV",
+	"Мальта":             "MT",
+	"Марокко":            "MA",
+	"Маршалловы Острова": "MH",
+	"Мексика":            "MX",
+	"Микронезия":         "FM",
+	"Мозамбик":           "MZ",
+	"Молдавия":           "MD",
+	"Молдова":            "MD",
+	"Монако":             "MC",
+	"Монголия":           "MN",
+	"Мьянма":             "MM",
+	"Намибия":            "NA",
+	"Науру":              "NR",
+	"Непал":              "NP",
+	"Нигер":              "NE",
+	"Нигерия":            "NG",
+	"Нидерланды":         "NL",
+	"Никарагуа":          "NI",
+	"Ниуэ":               "NU",
+	"Новая Зеландия":     "NZ",
+	"Норвегия":           "NO",
+	"ОАЭ":                "AE",
+	"Объединённые Арабские Эмираты": "AE",
+	"Оман":                        "OM",
+	"Острова Кайман":              "KY",
+	"Острова Кука":                "CK",
+	"Остров Норфолк":              "NF",
+	"Пакистан":                    "PK",
+	"Палау":                       "PW",
+	"Государство Палестина":       "PS",
+	"Палестина":                   "PS",
+	"Панама":                      "PA",
+	"Папуа — Новая Гвинея":        "PG",
+	"Парагвай":                    "PY",
+	"Перу":                        "PE",
+	"Польша":                      "PL",
+	"Португалия":                  "PT",
+	"Пуэрто-Рико":                 "PR",
+	"Республика Корея":            "KR",
+	"Южная Корея":                 "KR",
+	"Россия":                      "RU",
+	"Российская Федерация":        "RU",
+	"Руанда":                      "RW",
+	"Румыния":                     "RO",
+	"Сальвадор":                   "SV",
+	"Самоа":                       "WS",
+	"Западное Самоа":              "WS",
+	"Сан-Марино":                  "SM",
+	"Сан-Томе и Принсипи":         "ST",
+	"Саудовская Аравия":           "SA",
+	"Северная Македония":          "MK",
+	"Македония":                   "MK",
+	"Северные Марианские Острова": "MP",
+	"Сейшельские Острова":         "SC",
+	"Сейшельские острова":         "SC",
+	"Сенегал":                     "SN",
+	"Сент-Винсент и Гренадины":    "VC",
+	"Сент-Китс и Невис":           "KN",
+	"Сент-Люсия":                  "LC",
+	"Сербия":                      "RS",
+	"Сингапур":                    "SG",
+	"Сирия":                       "SY",
+	"Словакия":                    "SK",
+	"Словения":                    "SI",
+	"Соломоновы Острова":          "SB",
+	"Сомали":                      "SO",
+	"Судан":                       "SD",
+	"Суринам":                     "SR",
+	"США":                         "US",
+	"Сьерра-Леоне":                "SL",
+	"Таджикистан":                 "TJ",
+	"Таиланд":                     "TH",
+	"Танзания":                    "TZ",
+	"Того":                        "TG",
+	"Токелау":                     "TK",
+	"Тонга":                       "TO",
+	"Тринидад и Тобаго":           "TT",
+	"Тувалу":                      "TV",
+	"Тунис":                       "TN",
+	"Туркмения":                   "TM",
+	"Туркменистан":                "TM",
+	"Турция":                      "TR",
+	"Уганда":                      "UG",
+	"Узбекистан":                  "UZ",
+	"Украина":                     "UA",
+	"Уругвай":                     "UY",
+	"Фарерские острова":           "FO",
+	"Фиджи":                       "FJ",
+	"Филиппины":                   "PH",
+	"Финляндия":                   "FI",
+	"Франция":                     "FR",
+	"Хорватия":                    "HR",
+	"ЦАР":                         "CF",
+	"Центральноафриканская Республика": "CF",
+	"Чад":        "TD",
+	"Черногория": "ME",
+	"Чехия":      "CZ",
+	"Чили":       "CL",
+	"Швейцария":  "CH",
+	"Швеция":     "SE",
+	"Шри-Ланка":  "LK",
+	"Эквадор":    "EC",
+	"Экваториальная Гвинея": "GQ",
+	"Эритрея":   "ER",
+	"Эсватини":  "SZ",
+	"Свазиленд": "SZ",
+	"Эстония":   "EE",
+	"Эфиопия":   "ET",
+	"ЮАР":       "ZA",
+	"Южно-Африканская Республика": "ZA",
+	"Южный Судан":                 "SS",
+	"Ямайка":                      "JM",
+	"Япония":                      "JP",
+}
+
+// regionCountries maps regions which are mentioned without their country to the country code
+var regionCountries = map[string]string{
+	"Англия":              "GB",
+	"Шотландия":           "GB",
+	"Уэльс":               "GB",
+	"Северная Ирландия":   "GB",
+	"Гавайи":              "US",
+	"Каталония":           "ES",
+	"Андалусия":           "ES",
+	"Адыгея":              "RU",
+	"Башкортостан":        "RU",
+	"Башкирия":            "RU",
+	"Дагестан":            "RU",
+	"Ингушетия":           "RU",
+	"Кабардино-Балкария":  "RU",
+	"Калмыкия":            "RU",
+	"Карелия":             "RU",
+	"Марий Эл":            "RU",
+	"Республика Коми":     "RU",
+	"Республика Алтай":    "RU",
+	"Республика Хакасия":  "RU",
+	"Татарстан":           "RU",
+	"Удмуртия":            "RU",
+	"Чувашия":             "RU",
+	"Якутия":              "RU",
+	"Эвенкия":             "RU",
+	"Ненецкий АО":         "RU",
+	"Пермский край":       "RU",
+	"Оренбург":            "RU",
+	"Санкт-Петербург":     "RU",
+	"Москва":              "RU",
+	"Каракалпакстан":      "UZ",
+	"Республика Сербская": "BA",
+	"Тибет":               "CN",
+	"Квебек":              "CA",
+	"Ньюфаундленд":        "CA",
+	"Новая Шотландия":     "CA",
+	"Канарские острова":   "ES",
+	"Галисия":             "ES",
+	"Бретань":             "FR",
+	"Западная Бенгалия":   "IN",
+	"Гавайские острова":   "US",
+	"Аляска":              "US",
+	"Калифорния":          "US",
+	"Техас":               "US",
+	"Теннесси":            "US",
+	"Оклахома":            "US",
+	"Иллинойс":            "US",
+	"Джорджия":            "US",
+}
+
+// places which are mentioned as the country of a holiday but have no ISO 3166 code
+var uncodedPlaces = map[string]bool{
+	"Абхазия":                               true,
+	"Республика Абхазия":                    true,
+	"Республика Южная Осетия":               true,
+	"Приднестровская Молдавская Республика": true,
+	"Южная Осетия":                          true,
+	"Осетия":                                true,
+	"Приднестровье":                         true,
+	"ПМР":                                   true,
+	"НКР":                                   true,
+	"ДНР":                                   true,
+	"ЛНР":                                   true,
+	"Северный Кипр":                         true,
+	"Крым":                                  true,
+	"Крымские татары":                       true,
+	"СССР":                                  true,
+	"ГДР":                                   true,
+	"Чехословакия":                          true,
+	"Древний Рим":                           true,
+	"Европа":                                true,
+	"Европейский союз":                      true,
+	// peoples are named in the place of the country too
+	"Саамы":  true,
+	"Цыгане": true,
+}
+
+// CountryCode returns the ISO 3166-1 alpha-2 code of the country by its Russian name
+func CountryCode(name string) (string, bool) {
+	code, ok := countryCodes[normalizePlaceName(name)]
+	return code, ok
+}
+
+// RegionCountry returns the ISO 3166-1 alpha-2 code of the country the region belongs to
+func RegionCountry(name string) (string, bool) {
+	code, ok := regionCountries[normalizePlaceName(name)]
+	return code, ok
+}
+
+func normalizePlaceName(name string) string {
+	name = strings.Trim(name, " :,. ")
+	name = strings.TrimPrefix(name, "штат ")
+	name = strings.TrimPrefix(name, "штаты ")
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,126 @@
+package wiki
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Date is a day of the year without the year
+type Date struct {
+	Month time.Month
+	Day   int
+}
+
+func (date Date) before(other Date) bool {
+	return date.Month < other.Month || date.Month == other.Month && date.Day < other.Day
+}
+
+// Dataset answers the typed queries over the reports of a snapshot
+type Dataset struct {
+	days  []SnapshotDay
+	index map[Date]int
+
+	nameDaysOnce sync.Once
+	nameDays     *NameDayIndex
+}
+
+func NewDataset(snapshot *Snapshot) *Dataset {
+	dataset := &Dataset{days: snapshot.Days, index: map[Date]int{}}
+	for i, day := range snapshot.Days {
+		dataset.index[Date{day.Month, day.Day}] = i
+	}
+	return dataset
+}
+
+func LoadDatasetFile(path string) (*Dataset, error) {
+	snapshot, err := LoadSnapshotFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewDataset(snapshot), nil
+}
+
+// Day returns the report of the date or nil when the date is missing
+func (dataset *Dataset) Day(date Date) *Report {
+	if i, ok := dataset.index[date]; ok {
+		return &dataset.days[i].Report
+	}
+	return nil
+}
+
+// Range returns the days from the first date to the last one inclusive,
+// the range passes the new year when the last date is before the first one
+func (dataset *Dataset) Range(from Date, to Date) []SnapshotDay {
+	var days []SnapshotDay
+	wraps := to.before(from)
+	for _, day := range dataset.days {
+		date := Date{day.Month, day.Day}
+		afterFrom, beforeTo := !date.before(from), !to.before(date)
+		if wraps && (afterFrom || beforeTo) || !wraps && afterFrom && beforeTo {
+			days = append(days, day)
+		}
+	}
+	if wraps {
+		// the days of the next year follow the ones of the current year
+		for i, day := range days {
+			if !(Date{day.Month, day.Day}).before(from) {
+				return append(append([]SnapshotDay{}, days[i:]...), days[:i]...)
+			}
+		}
+	}
+	return days
+}
+
+func (dataset *Dataset) events(filter CalendarFilter, match func(event *CalendarEvent) bool) []CalendarEvent {
+	var events []CalendarEvent
+	for i := range dataset.days {
+		day := &dataset.days[i]
+		for _, event := range day.Report.CalendarEvents(day.Month, day.Day, filter) {
+			if match(&event) {
+				events = append(events, event)
+			}
+		}
+	}
+	return events
+}
+
+// Search returns the holidays of all the categories with the text in the title, the case is ignored
+func (dataset *Dataset) Search(text string) []CalendarEvent {
+	text = strings.ToLower(text)
+	return dataset.events(CalendarFilter{}, func(event *CalendarEvent) bool {
+		return strings.Contains(strings.ToLower(event.Summary), text)
+	})
+}
+
+// Profession returns the professional holidays with the profession in the title, e.g. "юрист"
+func (dataset *Dataset) Profession(profession string) []CalendarEvent {
+	profession = strings.ToLower(profession)
+	return dataset.events(CalendarFilter{Categories: []HolidayCategory{CategoryProfessional}}, func(event *CalendarEvent) bool {
+		return strings.Contains(strings.ToLower(event.Summary), profession)
+	})
+}
+
+// Confession returns all the religious holidays of the confession
+func (dataset *Dataset) Confession(confession Confession) []CalendarEvent {
+	filter := CalendarFilter{Categories: []HolidayCategory{CategoryReligious}, Confessions: []Confession{confession}}
+	return dataset.events(filter, func(event *CalendarEvent) bool {
+		return true
+	})
+}
+
+// NameDays returns the dates of the name days of the name, the other forms of the name
+// and the variants like "Сатурнин/Саторнин" are matched as NameDayIndex matches them
+func (dataset *Dataset) NameDays(name string) []Date {
+	dataset.nameDaysOnce.Do(func() {
+		dataset.nameDays = dataset.NameDayIndex()
+	})
+	var dates []Date
+	for _, nameDate := range dataset.nameDays.Lookup(name) {
+		// the dates are ordered, the same date repeats for the traditions and the forms of the name
+		if len(dates) == 0 || dates[len(dates)-1] != nameDate.Date {
+			dates = append(dates, nameDate.Date)
+		}
+	}
+	return dates
+}
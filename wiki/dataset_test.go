@@ -0,0 +1,105 @@
+package wiki
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testDataset() *Dataset {
+	snapshot := NewSnapshot(DefaultApiUrl)
+	snapshot.Add(time.January, 1, 0, Report{
+		HolidaysInt: []string{"Новый год"},
+		NameDays:    []string{"Илья", "Сатурнин/Саторнин"},
+	})
+	snapshot.Add(time.January, 7, 0, Report{
+		HolidaysRlg: ReligiousHolidays{Holidays: []*ReligiousHolidayDescr{
+			{Descriptions: []string{"Рождество Христово"}, GroupAbbr: "правосл.", Confession: ConfessionOrthodox},
+		}},
+	})
+	snapshot.Add(time.December, 3, 0, Report{
+		HolidaysProf: []string{"Россия — День юриста"},
+		NameDays:     []string{"Илья"},
+	})
+	snapshot.Add(time.December, 25, 0, Report{
+		HolidaysRlg: ReligiousHolidays{Holidays: []*ReligiousHolidayDescr{
+			{Descriptions: []string{"Рождество Христово"}, GroupAbbr: "катол.", Confession: ConfessionCatholic},
+		}},
+	})
+	return NewDataset(snapshot)
+}
+
+func datasetDates(days []SnapshotDay) []Date {
+	var dates []Date
+	for _, day := range days {
+		dates = append(dates, Date{day.Month, day.Day})
+	}
+	return dates
+}
+
+func TestDataset_Day(t *testing.T) {
+	dataset := testDataset()
+	if report := dataset.Day(Date{time.January, 1}); report == nil || report.HolidaysInt[0] != "Новый год" {
+		t.Error("Unexpected report:", report)
+	}
+	if report := dataset.Day(Date{time.January, 2}); report != nil {
+		t.Error("Expected missing day, got", report)
+	}
+}
+
+func TestDataset_Range(t *testing.T) {
+	dataset := testDataset()
+	expected := []Date{{time.January, 7}, {time.December, 3}}
+	if days := datasetDates(dataset.Range(Date{time.January, 2}, Date{time.December, 3})); !reflect.DeepEqual(expected, days) {
+		t.Error("Unexpected range:", days)
+	}
+	expected = []Date{{time.December, 25}, {time.January, 1}}
+	if days := datasetDates(dataset.Range(Date{time.December, 4}, Date{time.January, 6})); !reflect.DeepEqual(expected, days) {
+		t.Error("Unexpected range over the new year:", days)
+	}
+}
+
+func TestDataset_Search(t *testing.T) {
+	dataset := testDataset()
+	events := dataset.Search("рождество")
+	if len(events) != 2 || events[0].Day != 7 || events[1].Confession != ConfessionCatholic {
+		t.Error("Unexpected events:", events)
+	}
+	if events := dataset.Profession("Юриста"); len(events) != 1 || events[0].Month != time.December {
+		t.Error("Unexpected professional holidays:", events)
+	}
+	if events := dataset.Profession("Новый"); len(events) != 0 {
+		t.Error("Expected only professional holidays, got", events)
+	}
+	if events := dataset.Confession(ConfessionOrthodox); len(events) != 1 || events[0].Summary != "Рождество Христово (правосл.)" {
+		t.Error("Unexpected orthodox holidays:", events)
+	}
+}
+
+func TestDataset_NameDays(t *testing.T) {
+	dataset := testDataset()
+	expected := []Date{{time.January, 1}, {time.December, 3}}
+	if days := dataset.NameDays("илья"); !reflect.DeepEqual(expected, days) {
+		t.Error("Unexpected name days:", days)
+	}
+	if days := dataset.NameDays("Саторнин"); len(days) != 1 {
+		t.Error("Unexpected name days of the variant:", days)
+	}
+	if days := dataset.NameDays("Илюша"); !reflect.DeepEqual(expected, days) {
+		t.Error("Unexpected name days of the diminutive:", days)
+	}
+}
+
+func TestDataset_V1(t *testing.T) {
+	snapshot, err := LoadSnapshotFile("testdata/snapshot.v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataset := NewDataset(snapshot)
+	if events := dataset.Confession(ConfessionOrthodox); len(events) != 1 || events[0].Confession != ConfessionOrthodox {
+		t.Error("Unexpected orthodox holidays:", events)
+	}
+	if events := dataset.Confession(ConfessionCatholic); len(events) != 0 {
+		t.Error("Expected no catholic holidays, got", events)
+	}
+}
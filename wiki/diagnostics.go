@@ -0,0 +1,52 @@
+package wiki
+
+import "strconv"
+
+type WarningReason string
+
+const (
+	// header of the "== ... ==" level the parser does not know
+	UnknownHeader WarningReason = "unknown_header"
+	// subheader of the holidays section which does not map to any report field
+	UnknownSubheader WarningReason = "unknown_subheader"
+	// line that could not be assigned to any list of the report
+	UnparsedLine WarningReason = "unparsed_line"
+	// known section which is present in the article but nothing was taken from it
+	EmptySection WarningReason = "empty_section"
+)
+
+type Warning struct {
+	Line      int
+	Header    string
+	Subheader string
+	Reason    WarningReason
+	Text      string
+}
+
+func (warning *Warning) String() string {
+	context := warning.Header
+	if warning.Subheader != "" {
+		context += " / " + warning.Subheader
+	}
+	return strconv.Itoa(warning.Line) + ": " + string(warning.Reason) + " [" + context + "] " + warning.Text
+}
+
+// ParseResult is the parsed report together with the problems found in the article layout
+type ParseResult struct {
+	Report   Report
+	Warnings []Warning
+}
+
+func (result *ParseResult) HasWarnings() bool {
+	return len(result.Warnings) > 0
+}
+
+func (parser *Parser) warn(reason WarningReason, text string) {
+	parser.warnings = append(parser.warnings, Warning{
+		Line:      parser.lineNum,
+		Header:    parser.header,
+		Subheader: parser.subheader,
+		Reason:    reason,
+		Text:      text,
+	})
+}
@@ -0,0 +1,42 @@
+package wiki
+
+import "testing"
+
+func TestParseWithWarnings(t *testing.T) {
+	fullReport := `== Праздники и памятные дни ==
+
+=== Международные ===
+ ООН — Всемирный день борьбы со СПИДом
+
+=== Неофициальные ===
+ День чего-нибудь
+
+== Народные поверья ==
+Что-то
+
+
+== Приметы ==
+
+== См. также ==
+`
+	result, err := ParseWithWarnings(fullReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Warning{
+		{Line: 7, Header: holidaysHeader, Subheader: "Неофициальные", Reason: UnknownSubheader, Text: "Неофициальные"},
+		{Line: 9, Reason: UnknownHeader, Text: "Народные поверья"},
+		{Line: 13, Header: "Приметы", Reason: EmptySection},
+	}
+	if len(result.Warnings) != len(expected) {
+		t.Fatal("Expected:\n", expected, "\nActual:\n", result.Warnings)
+	}
+	for i := range expected {
+		if expected[i] != result.Warnings[i] {
+			t.Error("Expected:\n", expected[i], "\nActual:\n", result.Warnings[i])
+		}
+	}
+	if len(result.Report.HolidaysInt) != 1 {
+		t.Error("Unexpected report:", result.Report.HolidaysInt)
+	}
+}
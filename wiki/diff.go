@@ -0,0 +1,141 @@
+package wiki
+
+import (
+	"strings"
+	"unicode"
+)
+
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Reworded ChangeKind = "reworded"
+)
+
+// fields of the report compared by DiffReports
+const (
+	diffInternational = intSubsection
+	diffNational      = locSubsection
+	diffProfessional  = profSubsection
+	diffReligious     = rlgSubsection
+	diffNameDays      = nameDaysSubsection
+	diffOmens         = "omens"
+)
+
+// Change is a single difference of a report list, Old is empty for the added lines and New for the removed ones
+type Change struct {
+	Field string     `json:"field"`
+	Kind  ChangeKind `json:"kind"`
+	Old   string     `json:"old,omitempty"`
+	New   string     `json:"new,omitempty"`
+}
+
+func (change *Change) String() string {
+	switch change.Kind {
+	case Added:
+		return change.Field + " + " + change.New
+	case Removed:
+		return change.Field + " - " + change.Old
+	}
+	return change.Field + " ~ " + change.Old + " -> " + change.New
+}
+
+// the share of the common words for the removed and the added line to be taken for a rewording
+const rewordedSimilarity = 0.5
+
+func words(line string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		set[word] = true
+	}
+	return set
+}
+
+// similarity is the Jaccard index of the word sets of the lines
+func similarity(a, b string) float64 {
+	wordsA, wordsB := words(a), words(b)
+	common := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			common++
+		}
+	}
+	total := len(wordsA) + len(wordsB) - common
+	if total == 0 {
+		return 0
+	}
+	return float64(common) / float64(total)
+}
+
+func diffLists(field string, oldLines, newLines []string) []Change {
+	count := map[string]int{}
+	for _, line := range oldLines {
+		count[line]++
+	}
+	var added []string
+	for _, line := range newLines {
+		if count[line] > 0 {
+			count[line]--
+		} else {
+			added = append(added, line)
+		}
+	}
+	var removed []string
+	for _, line := range oldLines {
+		if count[line] > 0 {
+			count[line]--
+			removed = append(removed, line)
+		}
+	}
+
+	var changes []Change
+	paired := make([]bool, len(added))
+	for _, oldLine := range removed {
+		best, bestSimilarity := -1, rewordedSimilarity
+		for i, newLine := range added {
+			if s := similarity(oldLine, newLine); !paired[i] && s >= bestSimilarity {
+				best, bestSimilarity = i, s
+			}
+		}
+		if best < 0 {
+			changes = append(changes, Change{Field: field, Kind: Removed, Old: oldLine})
+			continue
+		}
+		paired[best] = true
+		changes = append(changes, Change{Field: field, Kind: Reworded, Old: oldLine, New: added[best]})
+	}
+	for i, newLine := range added {
+		if !paired[i] {
+			changes = append(changes, Change{Field: field, Kind: Added, New: newLine})
+		}
+	}
+	return changes
+}
+
+func religiousLines(holidays *ReligiousHolidays) []string {
+	var lines []string
+	for _, items := range holidays.Holidays {
+		for _, line := range items.Descriptions {
+			if items.GroupAbbr != "" {
+				line += " (" + items.GroupAbbr + ")"
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// DiffReports lists the lines of the holidays, name days and omens which were added, removed or reworded
+func DiffReports(oldReport, newReport *Report) []Change {
+	var changes []Change
+	changes = append(changes, diffLists(diffInternational, oldReport.HolidaysInt, newReport.HolidaysInt)...)
+	changes = append(changes, diffLists(diffNational, oldReport.HolidaysLoc, newReport.HolidaysLoc)...)
+	changes = append(changes, diffLists(diffProfessional, oldReport.HolidaysProf, newReport.HolidaysProf)...)
+	changes = append(changes, diffLists(diffReligious, religiousLines(&oldReport.HolidaysRlg), religiousLines(&newReport.HolidaysRlg))...)
+	changes = append(changes, diffLists(diffNameDays, oldReport.NameDays, newReport.NameDays)...)
+	changes = append(changes, diffLists(diffOmens, oldReport.Omens, newReport.Omens)...)
+	return changes
+}
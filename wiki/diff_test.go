@@ -0,0 +1,48 @@
+package wiki
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffReports(t *testing.T) {
+	oldReport := Report{
+		HolidaysInt:  []string{"Всемирный день борьбы со СПИДом", "День компьютерной графики"},
+		HolidaysProf: []string{"Россия — День юриста"},
+		HolidaysRlg: ReligiousHolidays{Holidays: []*ReligiousHolidayDescr{
+			{Descriptions: []string{"Память пророка Наума"}, GroupAbbr: "правосл."},
+		}},
+		NameDays: []string{"Антон", "Филарет"},
+	}
+	newReport := Report{
+		HolidaysInt:  []string{"Всемирный день борьбы со СПИДом", "Международный день компьютерной графики"},
+		HolidaysProf: []string{"Россия — День юриста"},
+		HolidaysRlg: ReligiousHolidays{Holidays: []*ReligiousHolidayDescr{
+			{Descriptions: []string{"Память пророка Наума"}, GroupAbbr: "правосл."},
+			{Descriptions: []string{"Память святого Элигия"}, GroupAbbr: "катол."},
+		}},
+		NameDays: []string{"Антон"},
+		Omens:    []string{"Наум — грамотник"},
+	}
+	expected := []Change{
+		{Field: "international", Kind: Reworded, Old: "День компьютерной графики", New: "Международный день компьютерной графики"},
+		{Field: "religious", Kind: Added, New: "Память святого Элигия (катол.)"},
+		{Field: "namedays", Kind: Removed, Old: "Филарет"},
+		{Field: "omens", Kind: Added, New: "Наум — грамотник"},
+	}
+	if changes := DiffReports(&oldReport, &newReport); !reflect.DeepEqual(expected, changes) {
+		t.Error("Unexpected changes:", changes)
+	}
+	if changes := DiffReports(&newReport, &newReport); len(changes) != 0 {
+		t.Error("Expected no changes, got", changes)
+	}
+}
+
+func TestDiffReports_Duplicates(t *testing.T) {
+	oldReport := Report{Omens: []string{"Тепло", "Тепло"}}
+	newReport := Report{Omens: []string{"Тепло"}}
+	expected := []Change{{Field: "omens", Kind: Removed, Old: "Тепло"}}
+	if changes := DiffReports(&oldReport, &newReport); !reflect.DeepEqual(expected, changes) {
+		t.Error("Unexpected changes:", changes)
+	}
+}
@@ -0,0 +1,51 @@
+package wiki
+
+import (
+	"time"
+	"wikiholidays/movable"
+)
+
+// the confessions of the religious section for the churches of the computus
+var churchConfessions = map[movable.Church]Confession{
+	movable.Orthodox: ConfessionOrthodox,
+	movable.Western:  ConfessionCatholic,
+}
+
+func confessionAbbr(confession Confession) string {
+	for _, rule := range getConfessionRules().Rules {
+		if rule.Id == confession {
+			return rule.Abbr
+		}
+	}
+	return ""
+}
+
+// AddMovableFeasts adds the feasts depending on Easter to the religious holidays of the day,
+// the articles of the fixed dates only mention them as the movable ones
+func (report *Report) AddMovableFeasts(day *time.Time) {
+	for _, feast := range movable.On(*day) {
+		confession := churchConfessions[feast.Church]
+		var group *ReligiousHolidayDescr
+		for _, item := range report.HolidaysRlg.Holidays {
+			if item.confession() == confession {
+				group = item
+				break
+			}
+		}
+		if group == nil {
+			group = &ReligiousHolidayDescr{GroupAbbr: confessionAbbr(confession), Confession: confession}
+			report.HolidaysRlg.Holidays = append(report.HolidaysRlg.Holidays, group)
+		}
+		exists := false
+		for _, line := range group.Descriptions {
+			if line == feast.Title {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			// the movable feast is the main one of the day
+			group.Descriptions = append([]string{feast.Title}, group.Descriptions...)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package wiki
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReport_AddMovableFeasts(t *testing.T) {
+	report := Report{HolidaysRlg: ReligiousHolidays{Holidays: []*ReligiousHolidayDescr{
+		{Descriptions: []string{"Память мученика Павла"}, GroupAbbr: "правосл.", Confession: ConfessionOrthodox},
+	}}}
+	day := time.Date(2025, time.April, 20, 9, 0, 0, 0, time.UTC)
+	report.AddMovableFeasts(&day)
+	// the second call does not repeat the feasts
+	report.AddMovableFeasts(&day)
+	expected := []*ReligiousHolidayDescr{
+		{Descriptions: []string{"Пасха (Светлое Христово Воскресение)", "Память мученика Павла"}, GroupAbbr: "правосл.", Confession: ConfessionOrthodox},
+		{Descriptions: []string{"Пасха (Воскресение Христово)"}, GroupAbbr: "катол.", Confession: ConfessionCatholic},
+	}
+	if !reflect.DeepEqual(expected, report.HolidaysRlg.Holidays) {
+		t.Error("Unexpected religious holidays:", report.String())
+	}
+
+	report = Report{}
+	day = time.Date(2025, time.April, 21, 9, 0, 0, 0, time.UTC)
+	report.AddMovableFeasts(&day)
+	if !report.HolidaysRlg.Empty() {
+		t.Error("Unexpected religious holidays:", report.String())
+	}
+}
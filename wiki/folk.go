@@ -0,0 +1,134 @@
+package wiki
+
+import (
+	"regexp"
+	"strings"
+)
+
+const sayingsSubheader = "Поговорки"
+const customsSubheader = "Обычаи"
+
+// FolkDay is the folk calendar section split into the names of the day and the kinds of its lines
+type FolkDay struct {
+	// names of the day in the folk calendar, e.g. "Никола Зимний"
+	Names []string
+	// weather and harvest omens
+	Omens []string
+	// proverbs and sayings
+	Sayings []string
+	// customs and beliefs of the day
+	Customs []string
+}
+
+func (folk *FolkDay) Empty() bool {
+	return len(folk.Names) == 0 && len(folk.Omens) == 0 && len(folk.Sayings) == 0 && len(folk.Customs) == 0
+}
+
+// the longest fragment of the first line taken for a name of the day
+const folkNameWords = 4
+
+var (
+	folkNameSplitRegex = regexp.MustCompile(`\.\s+|[.:]$|,\s+`)
+	// the lowercase letter before the dot keeps the initials like "А.С.Пушкин" whole
+	folkGluedRegex  = regexp.MustCompile(`[а-яё»)][.!?:][А-ЯЁ]`)
+	folkCustomRegex = regexp.MustCompile(`(?i)полагалось|принято|обычай|обряд|в старину|крестьян|приговарива|старались|нужно было|ходили|пекли|готовили|верили|считалось|считали`)
+	// the line without any marker is an omen anyway, the markers only keep the weather omens before the sayings
+	folkOmenRegex = regexp.MustCompile(`(?i)(^|\s)(если|коли|когда|ежели)\s|жди|будет|погод|урожа|мороз|дожд|снег|ветер|ветр|тепл|холод|гроз|иней|туман|морозн|примет|подмечали|замечали|гадают|гадали`)
+)
+
+func wordCount(line string) int {
+	return len(strings.Fields(line))
+}
+
+// folkNames splits the first line of the section into the names of the day,
+// nil is returned when the line is not a list of the short names
+func folkNames(line string) []string {
+	var names []string
+	for _, name := range folkNameSplitRegex.Split(strings.TrimSpace(line), -1) {
+		name = strings.Trim(name, "…,.:; ")
+		if name == "" {
+			continue
+		}
+		// "Фрол и Лавёр — лошадники": the dash is not a word
+		if wordCount(strings.Replace(name, "—", "", -1)) > folkNameWords {
+			return nil
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// a short line of two parts is taken for a proverb: "Каков Игнат, таков и август месяц"
+func isFolkSaying(line string) bool {
+	return wordCount(line) <= 8 && (strings.Contains(line, " — ") || strings.Count(line, ",") == 1 && !strings.HasSuffix(line, ":"))
+}
+
+func (report *Report) folkDay() FolkDay {
+	// the reports of the snapshots before the folk days were kept have only the flat list
+	if report.FolkDay.Empty() {
+		return NewFolkDay(report.Omens)
+	}
+	return report.FolkDay
+}
+
+// add puts the line of the section to its kind, the first line of the section may be the names of the day
+func (folk *FolkDay) add(line string, first bool) {
+	if first {
+		if names := folkNames(line); names != nil {
+			folk.Names = names
+			return
+		}
+	}
+	switch {
+	// the line before the list of the sayings ends with the colon, it is not a saying itself
+	case strings.HasPrefix(line, "«") && !strings.HasSuffix(line, ":"):
+		folk.Sayings = append(folk.Sayings, line)
+	case folkCustomRegex.MatchString(line):
+		folk.Customs = append(folk.Customs, line)
+	case folkOmenRegex.MatchString(line):
+		folk.Omens = append(folk.Omens, line)
+	case isFolkSaying(line):
+		folk.Sayings = append(folk.Sayings, line)
+	default:
+		// the section is the omens one, so the line without any marker is taken for an omen
+		folk.Omens = append(folk.Omens, line)
+	}
+}
+
+// splitGlued cuts the paragraphs the extract has glued without a space: "…зиму на ум наставили».Но когда…"
+func splitGlued(line string) []string {
+	var parts []string
+	start := 0
+	for _, index := range folkGluedRegex.FindAllStringIndex(line, -1) {
+		// the capital letter of two bytes starts the next paragraph
+		end := index[1] - 2
+		parts = append(parts, line[start:end])
+		start = end
+	}
+	return append(parts, line[start:])
+}
+
+// addFolkLine splits the line of the section as the article has it: every line, every "* " item
+// and every glued paragraph of the article is a separate entry of the folk day
+func (parser *Parser) addFolkLine(line string) {
+	folk := &parser.report.FolkDay
+	for _, item := range strings.Split(line, "* ") {
+		for _, part := range splitGlued(item) {
+			part = strings.Trim(part, "…,;. ")
+			if part == "" {
+				continue
+			}
+			folk.add(part, folk.Empty())
+		}
+	}
+}
+
+// NewFolkDay classifies the lines of the flat Report.Omens, the reports of the snapshots
+// before the folk days were kept have only them
+func NewFolkDay(omens []string) FolkDay {
+	folk := FolkDay{}
+	for i, line := range omens {
+		folk.add(line, i == 0)
+	}
+	return folk
+}
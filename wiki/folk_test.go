@@ -0,0 +1,123 @@
+package wiki
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewFolkDay(t *testing.T) {
+	folk := NewFolkDay([]string{
+		"Игнатий Богоносец.",
+		"Каков Игнат, таков и август месяц",
+		"Женщинам нужно было подготовить дом к празднику — вымыть пол, почистить да подкрасить",
+		"В этот день полагалось стряхивать иней с яблонь — для урожая",
+		"Подмечали, что чем сильнее морозы, тем жарче лето",
+		"«Введение пришло — зиму привело»",
+		"Зима в силу входит!",
+	})
+	expected := FolkDay{
+		Names:   []string{"Игнатий Богоносец"},
+		Omens:   []string{"Подмечали, что чем сильнее морозы, тем жарче лето", "Зима в силу входит!"},
+		Sayings: []string{"Каков Игнат, таков и август месяц", "«Введение пришло — зиму привело»"},
+		Customs: []string{
+			"Женщинам нужно было подготовить дом к празднику — вымыть пол, почистить да подкрасить",
+			"В этот день полагалось стряхивать иней с яблонь — для урожая",
+		},
+	}
+	if !reflect.DeepEqual(expected, folk) {
+		t.Error("Unexpected folk day:", folk)
+	}
+}
+
+func TestNewFolkDay_Names(t *testing.T) {
+	folk := NewFolkDay([]string{"Беды с домовым. Иоанн Лествичник. Простины Беломорья (северн.)."})
+	expected := []string{"Беды с домовым", "Иоанн Лествичник", "Простины Беломорья (северн.)"}
+	if !reflect.DeepEqual(expected, folk.Names) {
+		t.Error("Unexpected names:", folk.Names)
+	}
+	// the long first line is not a name of the day
+	folk = NewFolkDay([]string{"В старину верили, что в этот день нечисть на метлах летает, солнце замести пытается"})
+	if len(folk.Names) != 0 || len(folk.Customs) != 1 {
+		t.Error("Unexpected folk day:", folk)
+	}
+}
+
+func TestParse_FolkDay(t *testing.T) {
+	page, err := testSource.GetPage("10 декабря")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Parse(page.Extract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Романов день", "Роман Чудотворец"}
+	if !reflect.DeepEqual(expected, report.FolkDay.Names) {
+		t.Error("Unexpected names:", report.FolkDay.Names)
+	}
+	if len(report.FolkDay.Omens) == 0 {
+		t.Error("Expected omens, got", report.FolkDay)
+	}
+}
+
+func TestParse_FolkDayGlued(t *testing.T) {
+	page, err := testSource.GetPage("4 декабря")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Parse(page.Extract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the paragraphs glued in the extract are split, the lines before the lists of the sayings are not sayings
+	expected := []string{
+		"«Введенье, Ворота зимы». Народ приметил, что в это время бывают морозы:",
+		"Но когда на Введение оттепель, то говорили:",
+	}
+	if !reflect.DeepEqual(expected, report.FolkDay.Omens) {
+		t.Error("Unexpected omens:", report.FolkDay.Omens)
+	}
+	if len(report.FolkDay.Sayings) != 8 || report.FolkDay.Sayings[4] != "«Введенские морозы рукавицы на мужика надели, стужу установили, зиму на ум наставили»" {
+		t.Error("Unexpected sayings:", report.FolkDay.Sayings)
+	}
+	if len(report.FolkDay.Customs) != 1 {
+		t.Error("Unexpected customs:", report.FolkDay.Customs)
+	}
+	// the flat list keeps the lines as Report.String renders them
+	if len(report.Omens) != 9 {
+		t.Error("Unexpected flat omens:", report.Omens)
+	}
+}
+
+func TestMessageRenderer_SplitFolkDay(t *testing.T) {
+	report := Report{FolkDay: FolkDay{
+		Names:   []string{"Платон и Роман"},
+		Omens:   []string{"Зима в силу входит"},
+		Sayings: []string{"«Платон и Роман кажут зиму нам»"},
+		Customs: []string{"В этот день ходили на реку"},
+	}, Omens: []string{"Платон и Роман.", "Зима в силу входит"}}
+	expected := `
+*Приметы*
+
+_Платон и Роман_
+Зима в силу входит
+«Платон и Роман кажут зиму нам»
+`
+	validateStrings(t, expected, NewLegacyMarkdownRenderer().RenderWith(&report, RenderOptions{SplitFolkDay: true}))
+	validateStrings(t, expected, NewMarkdownV2Renderer().Render(&report))
+	expected = `
+<b>Приметы</b>
+
+<i>Платон и Роман</i>
+Зима в силу входит
+«Платон и Роман кажут зиму нам»
+`
+	validateStrings(t, expected, NewTelegramHTMLRenderer().Render(&report))
+	expected = `
+*Приметы*
+
+_Платон и Роман._
+Зима в силу входит
+`
+	validateStrings(t, expected, report.String())
+}
@@ -0,0 +1,43 @@
+//go:build ignore
+// +build ignore
+
+// gen_data writes the data files of the repository as the defaults built into the library,
+// go generate ./wiki after a file is changed, e.g. production_calendar.json for the next year
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+var files = []struct {
+	data   string
+	source string
+	name   string
+	doc    string
+}{
+	{"production_calendar.json", "production_calendar.go", "defaultProductionCalendar",
+		"the official calendars of the government decrees on the transfers of the days off"},
+	{"confession_rules.json", "confession_rules.go", "defaultConfessionRules",
+		"the rules of the confessions in the religious holidays"},
+}
+
+func main() {
+	for _, file := range files {
+		contents, err := ioutil.ReadFile("../" + file.data)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if strings.Contains(string(contents), "`") {
+			log.Fatal(file.data, ": the file can not be a raw string")
+		}
+		source := "// Code generated by gen_data.go from " + file.data + "; DO NOT EDIT.\n\n" +
+			"package wiki\n\n" +
+			"// " + file.doc + "\n" +
+			"const " + file.name + " = `" + string(contents) + "`\n"
+		if err := ioutil.WriteFile(file.source, []byte(source), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package wiki
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test ./wiki -record fetches every day article into testdata,
+// go test ./wiki -update regenerates the golden reports from the recorded extracts
+var record = flag.Bool("record", false, "fetch day articles from Wikipedia into testdata")
+var update = flag.Bool("update", false, "update golden reports in testdata")
+
+const goldenDir = "testdata"
+
+// the manifest pins every fixture to the revision of the article and the hash of the extract,
+// revision 0 marks the extracts which were taken from the hand-written parser tests, see testdata/README.md
+const manifestFile = "fixtures.json"
+
+type fixture struct {
+	Revision uint64 `json:"revision"`
+	Sha256   string `json:"sha256"`
+}
+
+func extractHash(extract string) string {
+	hash := sha256.Sum256([]byte(extract))
+	return hex.EncodeToString(hash[:])
+}
+
+func readManifest(t testing.TB) map[string]fixture {
+	contents, err := ioutil.ReadFile(filepath.Join(goldenDir, manifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := map[string]fixture{}
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+func writeManifest(t testing.TB, manifest map[string]fixture) {
+	// the keys of the map are written sorted
+	contents, err := json.MarshalIndent(manifest, "", " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(goldenDir, manifestFile), append(contents, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func recordPages(t *testing.T) {
+	source := NewHttpSource()
+	storage := DirSource{Dir: goldenDir}
+	manifest := readManifest(t)
+	defer writeManifest(t, manifest)
+	// 2020 is a leap year, so 29 февраля is recorded too
+	for day := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC); day.Year() == 2020; day = day.AddDate(0, 0, 1) {
+		page, err := source.GetPage(getDateString(&day))
+		if err != nil {
+			t.Error(getDateString(&day), ": ", err)
+			continue
+		}
+		if err := storage.SavePage(page); err != nil {
+			t.Fatal(err)
+		}
+		manifest[page.Title] = fixture{Revision: page.RevisionId, Sha256: extractHash(page.Extract)}
+	}
+}
+
+func TestFixtureManifest(t *testing.T) {
+	manifest := readManifest(t)
+	source := DirSource{Dir: goldenDir}
+	titles := recordedTitles(t)
+	for _, title := range titles {
+		page, err := source.GetPage(title)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pinned, ok := manifest[title]
+		if !ok {
+			t.Error(title, ": not in the manifest")
+			continue
+		}
+		if pinned.Sha256 != extractHash(page.Extract) {
+			t.Error(title, ": the extract differs from the pinned one")
+		}
+		if page.RevisionId != 0 && page.RevisionId != pinned.Revision {
+			t.Error(title, ": revision ", page.RevisionId, ", pinned ", pinned.Revision)
+		}
+	}
+	if len(manifest) != len(titles) {
+		t.Error("The manifest has ", len(manifest), " fixtures, testdata has ", len(titles))
+	}
+}
+
+func recordedTitles(t testing.TB) []string {
+	files, err := filepath.Glob(filepath.Join(goldenDir, "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, file := range files {
+		titles = append(titles, strings.TrimSuffix(filepath.Base(file), ".txt"))
+	}
+	return titles
+}
+
+func TestGoldenReports(t *testing.T) {
+	if *record {
+		recordPages(t)
+	}
+	source := DirSource{Dir: goldenDir}
+	for _, title := range recordedTitles(t) {
+		t.Run(title, func(t *testing.T) {
+			page, err := source.GetPage(title)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := Parse(page.Extract)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := json.MarshalIndent(report, "", " ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual = append(actual, '\n')
+
+			goldenFile := filepath.Join(goldenDir, title+".golden")
+			if *update {
+				if err := ioutil.WriteFile(goldenFile, actual, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := ioutil.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(expected, actual) {
+				validateStrings(t, string(expected), string(actual))
+			}
+		})
+	}
+}
+
+// BenchmarkParseFixtures parses every recorded extract once per iteration,
+// the corpus is the full year only after go test ./wiki -record
+func BenchmarkParseFixtures(b *testing.B) {
+	source := DirSource{Dir: goldenDir}
+	var extracts []string
+	for _, title := range recordedTitles(b) {
+		page, err := source.GetPage(title)
+		if err != nil {
+			b.Fatal(err)
+		}
+		extracts = append(extracts, page.Extract)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, extract := range extracts {
+			if _, err := Parse(extract); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
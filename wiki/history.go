@@ -0,0 +1,108 @@
+package wiki
+
+import (
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const eventsHeader = "События"
+const birthsHeader = "Родились"
+const deathsHeader = "Скончались"
+const historyHeader = "В этот день"
+
+// HistoryEntry is a single line of the events, births or deaths sections,
+// years before Christ are negative
+type HistoryEntry struct {
+	Year int
+	Text string
+}
+
+// HistoryGroup keeps the entries under one century or decade subheader
+type HistoryGroup struct {
+	Period  string
+	Entries []HistoryEntry
+}
+
+// the dash after the year is trimmed with the rest of the line when the events of the year follow on the next lines
+var historyYearRegex = regexp.MustCompile(`^(\d{1,4})( год[ау]?)?( до н\. ?э\.?)?(?:$|\s*[—–-]\s*(.*)$)`)
+
+func (parser *Parser) setHistoryHeader(header string, groups *[]*HistoryGroup) {
+	parser.setHeader(header, parser.parseHistory)
+	parser.history = groups
+	parser.historyGroup = nil
+}
+
+func (parser *Parser) setHistoryPeriod(period string) {
+	parser.subheader = strings.TrimSpace(period)
+	parser.historyGroup = nil
+}
+
+func (parser *Parser) parseHistory(line string) {
+	line = strings.Trim(line, ".;— ")
+	if line == "" || strings.HasPrefix(line, "См. также:") {
+		return
+	}
+	if parser.historyGroup == nil || parser.historyGroup.Period != parser.subheader {
+		parser.historyGroup = &HistoryGroup{Period: parser.subheader}
+		parser.historyYear = 0
+		*parser.history = append(*parser.history, parser.historyGroup)
+	}
+	if match := historyYearRegex.FindStringSubmatch(line); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		if match[3] != "" {
+			year = -year
+		}
+		parser.historyYear = year
+		line = strings.TrimSpace(match[4])
+		if line == "" {
+			// the events of the year follow on the next lines
+			return
+		}
+	} else if parser.historyYear == 0 {
+		parser.warn(UnparsedLine, line)
+		return
+	}
+	parser.historyGroup.Entries = append(parser.historyGroup.Entries, HistoryEntry{Year: parser.historyYear, Text: line})
+}
+
+func flattenHistory(groups []*HistoryGroup) []HistoryEntry {
+	var entries []HistoryEntry
+	for _, group := range groups {
+		entries = append(entries, group.Entries...)
+	}
+	return entries
+}
+
+// RandomEvents picks up to count events of the day ordered by year
+func (report *Report) RandomEvents(count int, random *rand.Rand) []HistoryEntry {
+	entries := flattenHistory(report.Events)
+	if count < 0 {
+		count = 0
+	}
+	if count < len(entries) {
+		random.Shuffle(len(entries), func(i, j int) {
+			entries[i], entries[j] = entries[j], entries[i]
+		})
+		entries = entries[:count]
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Year < entries[j].Year
+	})
+	return entries
+}
+
+func formatYear(year int) string {
+	if year < 0 {
+		return strconv.Itoa(-year) + " до н. э."
+	}
+	return strconv.Itoa(year)
+}
+
+// HistoryString formats the "this day in history" block with count random events
+func (report *Report) HistoryString(count int, random *rand.Rand) string {
+	options := RenderOptions{Sections: []RenderSection{SectionHistory}, HistoryEvents: count, Random: random}
+	return NewLegacyMarkdownRenderer().RenderWith(report, options)
+}
@@ -0,0 +1,123 @@
+package wiki
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+const historyTestReport = `== События ==
+См. также: Категория:События 1 января
+
+
+=== До XIX века ===
+45 год до н. э. — вступил в силу юлианский календарь.
+1700 — в России введено летоисчисление от Рождества Христова.
+
+
+=== XIX век ===
+1801 — образовано Соединённое королевство Великобритании и Ирландии.
+1804 — Гаити провозгласила независимость от Франции.
+
+
+=== XX век ===
+==== 1901—1919 ====
+1901 —
+образован Австралийский Союз;
+в Нигерии установлен британский протекторат.
+
+
+== Родились ==
+=== До XIX века ===
+1449 — Лоренцо Медичи (ум. 1492), флорентийский государственный деятель.
+
+
+== Скончались ==
+=== XX век ===
+1953 — Хэнк Уильямс (р. 1923), американский певец.
+`
+
+func TestParse_History(t *testing.T) {
+	report, err := Parse(historyTestReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedEvents := []*HistoryGroup{
+		{Period: "До XIX века", Entries: []HistoryEntry{
+			{-45, "вступил в силу юлианский календарь"},
+			{1700, "в России введено летоисчисление от Рождества Христова"},
+		}},
+		{Period: "XIX век", Entries: []HistoryEntry{
+			{1801, "образовано Соединённое королевство Великобритании и Ирландии"},
+			{1804, "Гаити провозгласила независимость от Франции"},
+		}},
+		{Period: "1901—1919", Entries: []HistoryEntry{
+			{1901, "образован Австралийский Союз"},
+			{1901, "в Нигерии установлен британский протекторат"},
+		}},
+	}
+	if !reflect.DeepEqual(expectedEvents, report.Events) {
+		t.Error("Expected:\n", expectedEvents, "\nActual:\n", report.Events)
+	}
+	expectedBirths := []*HistoryGroup{
+		{Period: "До XIX века", Entries: []HistoryEntry{{1449, "Лоренцо Медичи (ум. 1492), флорентийский государственный деятель"}}},
+	}
+	if !reflect.DeepEqual(expectedBirths, report.Births) {
+		t.Error("Expected:\n", expectedBirths, "\nActual:\n", report.Births)
+	}
+	expectedDeaths := []*HistoryGroup{
+		{Period: "XX век", Entries: []HistoryEntry{{1953, "Хэнк Уильямс (р. 1923), американский певец"}}},
+	}
+	if !reflect.DeepEqual(expectedDeaths, report.Deaths) {
+		t.Error("Expected:\n", expectedDeaths, "\nActual:\n", report.Deaths)
+	}
+}
+
+func TestReport_HistoryString(t *testing.T) {
+	report, err := Parse(historyTestReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	random := rand.New(rand.NewSource(1))
+	if events := report.RandomEvents(3, random); len(events) != 3 {
+		t.Error("Expected 3 events, got", events)
+	} else if events[0].Year > events[1].Year || events[1].Year > events[2].Year {
+		t.Error("Events must be ordered by year:", events)
+	}
+
+	report.Events = report.Events[:1]
+	expected := `
+*В этот день*
+- 45 до н. э. — вступил в силу юлианский календарь
+- 1700 — в России введено летоисчисление от Рождества Христова
+`
+	validateStrings(t, expected, report.HistoryString(3, random))
+}
+
+func TestReport_RandomEventsNegative(t *testing.T) {
+	report, err := Parse(historyTestReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events := report.RandomEvents(-1, rand.New(rand.NewSource(1))); len(events) != 0 {
+		t.Error("Expected no events, got", events)
+	}
+}
+
+func TestRenderer_HistorySection(t *testing.T) {
+	report, err := Parse(historyTestReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderer := NewLegacyMarkdownRenderer()
+	options := RenderOptions{HistoryEvents: 3, Random: rand.New(rand.NewSource(1))}
+	expected := report.String() + report.HistoryString(3, rand.New(rand.NewSource(1)))
+	validateStrings(t, expected, renderer.RenderWith(&report, options))
+
+	if rendered := renderer.Render(&report); rendered != report.String() {
+		t.Error("History must be left out by default:\n", rendered)
+	}
+
+	options = RenderOptions{Sections: []RenderSection{SectionHistory}, HistoryEvents: 3, Random: rand.New(rand.NewSource(1))}
+	validateStrings(t, report.HistoryString(3, rand.New(rand.NewSource(1))), renderer.RenderWith(&report, options))
+}
@@ -0,0 +1,165 @@
+package wiki
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type HolidayCategory string
+
+const (
+	CategoryInternational HolidayCategory = "international"
+	CategoryNational      HolidayCategory = "national"
+	CategoryProfessional  HolidayCategory = "professional"
+	CategoryReligious     HolidayCategory = "religious"
+)
+
+var categoryTitles = map[HolidayCategory]string{
+	CategoryInternational: intHolidaysSubheader,
+	CategoryNational:      locHolidaysSubheader,
+	CategoryProfessional:  profHolidaysSubheader,
+	CategoryReligious:     rlgHolidaysSubheader,
+}
+
+// CalendarEvent is a holiday which repeats every year on the same date
+type CalendarEvent struct {
+	Month      time.Month
+	Day        int
+	Summary    string
+	Category   HolidayCategory
+	Confession Confession
+}
+
+// CalendarFilter selects the events by category and the religious ones by confession,
+// empty lists select everything
+type CalendarFilter struct {
+	Categories  []HolidayCategory
+	Confessions []Confession
+}
+
+func (filter *CalendarFilter) category(category HolidayCategory) bool {
+	if len(filter.Categories) == 0 {
+		return true
+	}
+	for _, selected := range filter.Categories {
+		if selected == category {
+			return true
+		}
+	}
+	return false
+}
+
+func (filter *CalendarFilter) confession(confession Confession) bool {
+	if len(filter.Confessions) == 0 {
+		return true
+	}
+	for _, selected := range filter.Confessions {
+		if selected == confession {
+			return true
+		}
+	}
+	return false
+}
+
+// CalendarEvents lists the holidays of the report which pass the filter
+func (report *Report) CalendarEvents(month time.Month, day int, filter CalendarFilter) []CalendarEvent {
+	var events []CalendarEvent
+	add := func(category HolidayCategory, lines []string) {
+		if !filter.category(category) {
+			return
+		}
+		for _, line := range lines {
+			events = append(events, CalendarEvent{Month: month, Day: day, Summary: line, Category: category})
+		}
+	}
+	add(CategoryInternational, report.HolidaysInt)
+	add(CategoryNational, report.HolidaysLoc)
+	add(CategoryProfessional, report.HolidaysProf)
+	if filter.category(CategoryReligious) {
+		for _, items := range report.HolidaysRlg.Holidays {
+			confession := items.confession()
+			if !filter.confession(confession) {
+				continue
+			}
+			for _, line := range items.Descriptions {
+				if items.GroupAbbr != "" {
+					line += " (" + items.GroupAbbr + ")"
+				}
+				events = append(events, CalendarEvent{Month: month, Day: day, Summary: line, Category: CategoryReligious, Confession: confession})
+			}
+		}
+	}
+	return events
+}
+
+func (event *CalendarEvent) uid() string {
+	hash := sha1.Sum([]byte(fmt.Sprintf("%d-%d-%s-%s", event.Month, event.Day, event.Category, event.Summary)))
+	return hex.EncodeToString(hash[:]) + "@wikiholidays"
+}
+
+var icalTextReplacer = strings.NewReplacer(
+	"\\", "\\\\",
+	";", "\\;",
+	",", "\\,",
+	"\n", "\\n",
+)
+
+// foldICalLine splits the content line into the lines of at most 75 octets (RFC 5545, 3.1)
+func foldICalLine(line string) string {
+	var folded strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// the leading space of the continuation line is counted too
+		limit = 74
+	}
+	folded.WriteString(line)
+	return folded.String()
+}
+
+// WriteICalendar writes the events as an RFC 5545 calendar with one all-day yearly event per holiday,
+// stamp is used as DTSTAMP of the events
+func WriteICalendar(writer io.Writer, name string, events []CalendarEvent, stamp time.Time) error {
+	buffered := bufio.NewWriter(writer)
+	write := func(line string) {
+		buffered.WriteString(foldICalLine(line) + "\r\n")
+	}
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:-//wikiholidays//RU")
+	write("CALSCALE:GREGORIAN")
+	write("METHOD:PUBLISH")
+	write("X-WR-CALNAME:" + icalTextReplacer.Replace(name))
+	dtstamp := stamp.UTC().Format("20060102T150405Z")
+	for _, event := range events {
+		// 2000 is a leap year, so the events of 29 февраля repeat every leap year
+		start := time.Date(2000, event.Month, event.Day, 0, 0, 0, 0, time.UTC)
+		categories := icalTextReplacer.Replace(categoryTitles[event.Category])
+		if title, ok := confessionTitles[event.Confession]; ok {
+			categories += "," + icalTextReplacer.Replace(title)
+		}
+		write("BEGIN:VEVENT")
+		write("UID:" + event.uid())
+		write("DTSTAMP:" + dtstamp)
+		write("DTSTART;VALUE=DATE:" + start.Format("20060102"))
+		write("DTEND;VALUE=DATE:" + start.AddDate(0, 0, 1).Format("20060102"))
+		write("RRULE:FREQ=YEARLY")
+		write("SUMMARY:" + icalTextReplacer.Replace(event.Summary))
+		write("CATEGORIES:" + categories)
+		write("TRANSP:TRANSPARENT")
+		write("END:VEVENT")
+	}
+	write("END:VCALENDAR")
+	return buffered.Flush()
+}
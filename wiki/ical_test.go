@@ -0,0 +1,117 @@
+package wiki
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReport_CalendarEvents(t *testing.T) {
+	report := Report{
+		HolidaysInt:  []string{"Всемирный день борьбы со СПИДом"},
+		HolidaysProf: []string{"Россия — День юриста"},
+		HolidaysRlg: ReligiousHolidays{Holidays: []*ReligiousHolidayDescr{
+			{Descriptions: []string{"Введение во храм Пресвятой Богородицы"}, GroupAbbr: "правосл.", Confession: ConfessionOrthodox},
+			{Descriptions: []string{"Анно II"}, GroupAbbr: "катол.", Confession: ConfessionCatholic},
+		}},
+	}
+	events := report.CalendarEvents(time.December, 4, CalendarFilter{})
+	if len(events) != 4 {
+		t.Error("Expected all the events, got", events)
+	}
+	events = report.CalendarEvents(time.December, 4, CalendarFilter{Categories: []HolidayCategory{CategoryProfessional}})
+	if len(events) != 1 || events[0].Summary != "Россия — День юриста" {
+		t.Error("Expected professional holiday, got", events)
+	}
+	events = report.CalendarEvents(time.December, 4, CalendarFilter{
+		Categories:  []HolidayCategory{CategoryReligious},
+		Confessions: []Confession{ConfessionOrthodox},
+	})
+	if len(events) != 1 || events[0].Summary != "Введение во храм Пресвятой Богородицы (правосл.)" || events[0].Confession != ConfessionOrthodox {
+		t.Error("Expected orthodox holiday, got", events)
+	}
+}
+
+func TestReport_CalendarEventsV1(t *testing.T) {
+	filter := CalendarFilter{Categories: []HolidayCategory{CategoryReligious}, Confessions: []Confession{ConfessionOrthodox}}
+	expected := "Рождество Христово — в церквях, пользующихся юлианским календарём: Русской, Иерусалимской, Грузинской, Сербской, Польской (правосл.)"
+	check := func(report *Report) {
+		events := report.CalendarEvents(time.January, 7, filter)
+		if len(events) != 1 || events[0].Summary != expected || events[0].Confession != ConfessionOrthodox {
+			t.Error("Expected orthodox holiday, got", events)
+		}
+	}
+
+	snapshot, err := LoadSnapshotFile("testdata/snapshot.v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(&snapshot.Day(time.January, 7).Report)
+
+	// the reports read without the migration have only the abbreviations
+	contents, err := ioutil.ReadFile("testdata/snapshot.v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	months := map[string]map[string]snapshotV1Day{}
+	if err := json.Unmarshal(contents, &months); err != nil {
+		t.Fatal(err)
+	}
+	day := months["1"]["7"]
+	check(&day.Report)
+}
+
+func TestWriteICalendar(t *testing.T) {
+	events := []CalendarEvent{
+		{Month: time.February, Day: 29, Summary: "День мира; второй, третий", Category: CategoryInternational},
+		{Month: time.December, Day: 4, Summary: "Введение во храм Пресвятой Богородицы (правосл.)", Category: CategoryReligious, Confession: ConfessionOrthodox},
+	}
+	var buffer bytes.Buffer
+	stamp := time.Date(2019, time.December, 1, 10, 0, 0, 0, time.UTC)
+	if err := WriteICalendar(&buffer, "Праздники", events, stamp); err != nil {
+		t.Fatal(err)
+	}
+	expected := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//wikiholidays//RU\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"METHOD:PUBLISH\r\n" +
+		"X-WR-CALNAME:Праздники\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:" + events[0].uid() + "\r\n" +
+		"DTSTAMP:20191201T100000Z\r\n" +
+		"DTSTART;VALUE=DATE:20000229\r\n" +
+		"DTEND;VALUE=DATE:20000301\r\n" +
+		"RRULE:FREQ=YEARLY\r\n" +
+		"SUMMARY:День мира\\; второй\\, третий\r\n" +
+		"CATEGORIES:Международные\r\n" +
+		"TRANSP:TRANSPARENT\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:" + events[1].uid() + "\r\n" +
+		"DTSTAMP:20191201T100000Z\r\n" +
+		"DTSTART;VALUE=DATE:20001204\r\n" +
+		"DTEND;VALUE=DATE:20001205\r\n" +
+		"RRULE:FREQ=YEARLY\r\n" +
+		"SUMMARY:Введение во храм Пресвятой Богороди\r\n цы (правосл.)\r\n" +
+		"CATEGORIES:Религиозные,Православие\r\n" +
+		"TRANSP:TRANSPARENT\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	validateStrings(t, expected, buffer.String())
+}
+
+func TestFoldICalLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("д", 100)
+	for _, folded := range strings.Split(foldICalLine(line), "\r\n") {
+		if len(folded) > 75 {
+			t.Error("Line is longer than 75 octets:", len(folded))
+		}
+	}
+	if unfolded := strings.Replace(foldICalLine(line), "\r\n ", "", -1); unfolded != line {
+		t.Error("Unexpected unfolded line:", unfolded)
+	}
+}
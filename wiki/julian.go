@@ -0,0 +1,50 @@
+package wiki
+
+import (
+	"strconv"
+	"time"
+)
+
+// JulianDate is a date of the Julian (old style) calendar, it may be 29 февраля
+// of the years which are not leap in the Gregorian calendar
+type JulianDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// the Julian day number of 1 января 1970 года
+const unixEpochJulianDay = 2440588
+
+func julianDayNumber(year int, month time.Month, day int) int {
+	a := (14 - int(month)) / 12
+	y := year + 4800 - a
+	m := int(month) + 12*a - 3
+	return day + (153*m+2)/5 + 365*y + y/4 - 32083
+}
+
+// ToJulian converts the date of the Gregorian calendar to the Julian one
+func ToJulian(day time.Time) JulianDate {
+	year, month, dayNum := day.Date()
+	days := time.Date(year, month, dayNum, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+	c := int(days) + unixEpochJulianDay + 32082
+	d := (4*c + 3) / 1461
+	e := c - 1461*d/4
+	m := (5*e + 2) / 153
+	return JulianDate{
+		Year:  d - 4800 + m/10,
+		Month: time.Month(m + 3 - 12*(m/10)),
+		Day:   e - (153*m+2)/5 + 1,
+	}
+}
+
+// Gregorian converts the date to the Gregorian calendar, the time is midnight UTC
+func (date JulianDate) Gregorian() time.Time {
+	days := julianDayNumber(date.Year, date.Month, date.Day) - unixEpochJulianDay
+	return time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+}
+
+// String formats the date as "19 декабря 2019 года"
+func (date JulianDate) String() string {
+	return strconv.Itoa(date.Day) + " " + monthsGenitive[date.Month-1] + " " + strconv.Itoa(date.Year) + " года"
+}
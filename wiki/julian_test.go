@@ -0,0 +1,53 @@
+package wiki
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToJulian(t *testing.T) {
+	for _, test := range []struct {
+		gregorian time.Time
+		julian    JulianDate
+	}{
+		{time.Date(1918, time.February, 14, 0, 0, 0, 0, time.UTC), JulianDate{1918, time.February, 1}},
+		{time.Date(1900, time.March, 13, 0, 0, 0, 0, time.UTC), JulianDate{1900, time.February, 29}},
+		{time.Date(1582, time.October, 15, 0, 0, 0, 0, time.UTC), JulianDate{1582, time.October, 5}},
+		{time.Date(2020, time.January, 7, 23, 0, 0, 0, time.UTC), JulianDate{2019, time.December, 25}},
+		{time.Date(2100, time.March, 14, 0, 0, 0, 0, time.UTC), JulianDate{2100, time.February, 29}},
+		{time.Date(2100, time.March, 15, 0, 0, 0, 0, time.UTC), JulianDate{2100, time.March, 1}},
+	} {
+		if julian := ToJulian(test.gregorian); julian != test.julian {
+			t.Error("Unexpected Julian date of", test.gregorian.Format("2006-01-02"), julian)
+		}
+		if gregorian := test.julian.Gregorian(); !gregorian.Equal(time.Date(test.gregorian.Year(), test.gregorian.Month(), test.gregorian.Day(), 0, 0, 0, 0, time.UTC)) {
+			t.Error("Unexpected Gregorian date of", test.julian, gregorian)
+		}
+	}
+}
+
+func TestJulianDate_RoundTrip(t *testing.T) {
+	for day := time.Date(1600, time.January, 1, 0, 0, 0, 0, time.UTC); day.Year() < 2200; day = day.AddDate(0, 0, 7) {
+		if gregorian := ToJulian(day).Gregorian(); !gregorian.Equal(day) {
+			t.Fatal("Unexpected round trip of", day.Format("2006-01-02"), gregorian)
+		}
+	}
+}
+
+func TestGenerateCalendarStats_JulianDate(t *testing.T) {
+	day := time.Date(2019, time.December, 1, 1, 1, 1, 1, time.UTC)
+	expected := `*Воскресенье, 1 декабря 2019 года (18 ноября по старому стилю)*
+335-й день года. До конца года 30 дней
+`
+	validateStrings(t, expected, GenerateCalendarStatsWith(&day, CalendarStatsOptions{JulianDate: true}))
+
+	day = time.Date(2020, time.January, 7, 1, 1, 1, 1, time.UTC)
+	expected = `*Вторник, 7 января 2020 года (25 декабря 2019 года по старому стилю)*
+7-й день года. До конца года 359 дней
+`
+	validateStrings(t, expected, GenerateCalendarStatsWith(&day, CalendarStatsOptions{JulianDate: true}))
+	if stats := GenerateCalendarStats(&day); strings.Contains(stats, "стилю") {
+		t.Error("Unexpected Julian date:", stats)
+	}
+}
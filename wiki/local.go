@@ -0,0 +1,164 @@
+package wiki
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LocalHoliday is an entry of the national holidays with the country split from the title.
+// Countries keeps ISO 3166-1 alpha-2 codes, Region keeps the places which are not countries
+type LocalHoliday struct {
+	Countries []string
+	Region    string
+	Title     string
+}
+
+var localPlaceRegex = regexp.MustCompile(` ?[—–] ?| - `)
+
+// splitTopLevel splits the line by the separator ignoring the ones in parentheses
+func splitTopLevel(line string, separator byte) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case separator:
+			if depth == 0 {
+				parts = append(parts, line[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, line[start:])
+}
+
+type place struct {
+	countries []string
+	regions   []string
+	known     bool
+}
+
+func (p *place) addCountry(code string) {
+	for _, existed := range p.countries {
+		if existed == code {
+			return
+		}
+	}
+	p.countries = append(p.countries, code)
+}
+
+func (p *place) resolved() bool {
+	return p.known
+}
+
+func (p *place) parse(name string) {
+	name = normalizePlaceName(name)
+	if name == "" || name == "др" || name == "и др" {
+		return
+	}
+	if code, ok := CountryCode(name); ok {
+		p.addCountry(code)
+		p.known = true
+		return
+	}
+	if code, ok := RegionCountry(name); ok {
+		p.addCountry(code)
+		p.regions = append(p.regions, name)
+		p.known = true
+		return
+	}
+	if uncodedPlaces[name] {
+		p.regions = append(p.regions, name)
+		p.known = true
+		return
+	}
+	if open := strings.Index(name, "("); open >= 0 {
+		if end := strings.LastIndex(name, ")"); end > open {
+			p.parse(name[:open] + name[end+1:])
+			for _, inner := range splitTopLevel(name[open+1:end], ',') {
+				p.parse(inner)
+			}
+			return
+		}
+	}
+	if parts := strings.Split(name, " и "); len(parts) > 1 {
+		for _, part := range parts {
+			p.parse(part)
+		}
+		return
+	}
+	p.regions = append(p.regions, name)
+}
+
+func parsePlace(line string) place {
+	p := place{}
+	for _, part := range splitTopLevel(strings.Replace(line, ": ", ", ", -1), ',') {
+		p.parse(part)
+	}
+	return p
+}
+
+// isPlaceLine checks lines like "Россия:" which name the country of the following entries
+func isPlaceLine(line string) ([]string, bool) {
+	if localPlaceRegex.MatchString(line) {
+		return nil, false
+	}
+	p := parsePlace(line)
+	if !p.resolved() || len(p.countries) == 0 || len(p.regions) > 0 {
+		return nil, false
+	}
+	return p.countries, true
+}
+
+func (parser *Parser) addLocalHoliday(line string) {
+	if countries, ok := isPlaceLine(line); ok {
+		parser.localCountries = countries
+		return
+	}
+	holiday := LocalHoliday{Title: line}
+	var p place
+	separators := localPlaceRegex.FindAllStringIndex(line, -1)
+	for i, index := range separators {
+		// the dash may be a part of the country name as in "Папуа — Новая Гвинея"
+		p = parsePlace(line[:index[0]])
+		if p.resolved() || (i == 0 && parser.subsection == regSubsection) {
+			holiday.Title = strings.TrimSpace(line[index[1]:])
+			break
+		}
+		p = place{}
+	}
+	if len(separators) == 0 {
+		// "Египет: день, посвящённый богине Сехмет"
+		if parts := strings.SplitN(line, ": ", 2); len(parts) == 2 {
+			if colon := parsePlace(parts[0]); colon.resolved() {
+				p = colon
+				holiday.Title = strings.TrimSpace(parts[1])
+			}
+		}
+	}
+	if len(separators) == 0 && holiday.Title == line {
+		if parts := strings.SplitN(line, ", ", 2); len(parts) == 2 {
+			if code, ok := CountryCode(parts[0]); ok {
+				p.addCountry(code)
+				holiday.Title = strings.TrimSpace(parts[1])
+			}
+		}
+	}
+	holiday.Countries = p.countries
+	holiday.Region = strings.Join(p.regions, ", ")
+	if len(holiday.Countries) == 0 {
+		// the place of the line like "Приднестровье" replaces the country of the preceding "Россия"
+		if parser.localCountries != nil && !p.resolved() {
+			holiday.Countries = append([]string{}, parser.localCountries...)
+		} else if parser.subsection == regSubsection {
+			holiday.Countries = []string{"RU"}
+		}
+	}
+	parser.report.LocalHolidays = append(parser.report.LocalHolidays, holiday)
+}
@@ -0,0 +1,68 @@
+package wiki
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse_LocalHolidays(t *testing.T) {
+	fullReport := `== Праздники и памятные дни ==
+
+=== Национальные ===
+ Куба — День освобождения.
+ Россия,  Белоруссия — День памяти воинов-интернационалистов.
+ Папуа — Новая Гвинея — День Покаяния.
+ США, Техас — День Сан-Хасинто.
+ Испания, День Конституции.
+ Абхазия — День флага.
+Россия:
+День подразделений специального назначения;
+ Приднестровье — День Конституции.
+ Теннесси ( США) — День Натана Форреста.
+ Египет: день богини Сехмет.
+ Республика Абхазия — День независимости Республики Абхазия.
+
+=== Региональные ===
+ Татарстан — День родного языка.
+`
+	report, err := Parse(fullReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []LocalHoliday{
+		{Countries: []string{"CU"}, Title: "День освобождения"},
+		{Countries: []string{"RU", "BY"}, Title: "День памяти воинов-интернационалистов"},
+		{Countries: []string{"PG"}, Title: "День Покаяния"},
+		{Countries: []string{"US"}, Region: "Техас", Title: "День Сан-Хасинто"},
+		{Countries: []string{"ES"}, Title: "День Конституции"},
+		{Region: "Абхазия", Title: "День флага"},
+		{Countries: []string{"RU"}, Title: "День подразделений специального назначения"},
+		{Region: "Приднестровье", Title: "День Конституции"},
+		{Countries: []string{"US"}, Region: "Теннесси", Title: "День Натана Форреста"},
+		{Countries: []string{"EG"}, Title: "день богини Сехмет"},
+		{Region: "Республика Абхазия", Title: "День независимости Республики Абхазия"},
+		{Countries: []string{"RU"}, Region: "Татарстан", Title: "День родного языка"},
+	}
+	if !reflect.DeepEqual(expected, report.LocalHolidays) {
+		t.Error("Expected:\n", expected, "\nActual:\n", report.LocalHolidays)
+	}
+	if len(report.HolidaysLoc) != 13 {
+		t.Error("Flat list must keep all the lines:", report.HolidaysLoc)
+	}
+}
+
+func TestCountryCode(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Россия": "RU",
+		"Китайская Республика (Тайвань)": "TW",
+		"Босния и Герцеговина":           "BA",
+		" Белоруссия:":                   "BY",
+	} {
+		if code, ok := CountryCode(name); !ok || code != expected {
+			t.Error(name, ": expected ", expected, ", got ", code)
+		}
+	}
+	if _, ok := CountryCode("Древний Рим"); ok {
+		t.Error("Древний Рим has no ISO code")
+	}
+}
@@ -0,0 +1,418 @@
+package wiki
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const nameDaysTitle = "Именины"
+const nameDaysNotFound = "В календаре нет именин с этим именем"
+const otherTradition = "Без указания традиции"
+
+type Gender string
+
+const (
+	GenderMale   Gender = "male"
+	GenderFemale Gender = "female"
+)
+
+// CalendarStyle is the calendar the date of the name day is given in
+type CalendarStyle string
+
+const (
+	// the Gregorian calendar, "по новому стилю"
+	NewStyle CalendarStyle = "new"
+	// the Julian calendar, "по старому стилю"
+	OldStyle CalendarStyle = "old"
+)
+
+var calendarStyleTitles = map[CalendarStyle]string{
+	NewStyle: "по новому стилю",
+	OldStyle: "по старому стилю",
+}
+
+// NameDay is a name of the name days section with the tradition of the calendar it comes from,
+// the tradition and the gender are empty when the article does not tell them
+type NameDay struct {
+	Name      string
+	Gender    Gender
+	Tradition Confession
+	// the explanation after the dash, e.g. "мученик Бон"
+	Note string
+	// the calendar told by the article, empty when the article does not tell it
+	Style CalendarStyle
+}
+
+// the titles of the traditions in the name days section
+var nameTraditionTitles = map[Confession]string{
+	ConfessionOrthodox: "Православные",
+	ConfessionCatholic: "Католические",
+}
+
+func (parser *Parser) setNameMarker(marker string) {
+	switch {
+	case strings.HasPrefix(marker, "Католич"):
+		parser.nameTradition = ConfessionCatholic
+		parser.nameGender = ""
+		parser.nameStyle = ""
+	case strings.HasPrefix(marker, "Православ"):
+		parser.nameTradition = ConfessionOrthodox
+		parser.nameGender = ""
+		parser.nameStyle = ""
+	case strings.HasPrefix(marker, "Мужские"):
+		parser.nameGender = GenderMale
+	case strings.HasPrefix(marker, "Женские"):
+		parser.nameGender = GenderFemale
+	}
+	for style, title := range calendarStyleTitles {
+		if strings.Contains(marker, title) {
+			parser.nameStyle = style
+		}
+	}
+}
+
+// addNameEntries keeps the names of every tradition even when the flat NameDays already has them
+func (parser *Parser) addNameEntries(names []string) {
+	for _, name := range names {
+		if name == "имя" {
+			continue
+		}
+		entry := NameDay{Name: name, Gender: parser.nameGender, Tradition: parser.nameTradition, Note: parser.nameNote, Style: parser.nameStyle}
+		exists := false
+		for _, existed := range parser.report.NameDayEntries {
+			if existed.Name == entry.Name && existed.Tradition == entry.Tradition {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			parser.report.NameDayEntries = append(parser.report.NameDayEntries, entry)
+		}
+	}
+}
+
+func (report *Report) hasNameTraditions() bool {
+	for _, entry := range report.NameDayEntries {
+		if entry.Tradition != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type nameDaysGroup struct {
+	tradition Confession
+	style     CalendarStyle
+	names     []string
+}
+
+// nameDaysByTradition groups the names in the order the traditions appear in the article
+func (report *Report) nameDaysByTradition() []nameDaysGroup {
+	var groups []nameDaysGroup
+	for _, entry := range report.NameDayEntries {
+		i := 0
+		for i < len(groups) && groups[i].tradition != entry.Tradition {
+			i++
+		}
+		if i == len(groups) {
+			groups = append(groups, nameDaysGroup{tradition: entry.Tradition})
+		}
+		if groups[i].style == "" {
+			groups[i].style = entry.Style
+		}
+		groups[i].names = append(groups[i].names, entry.Name)
+	}
+	return groups
+}
+
+var nameReplacer = strings.NewReplacer("ё", "е", "(", "", ")", "")
+
+// normalizeName makes the lookup key of the name: "Пётр" -> "петр"
+func normalizeName(name string) string {
+	return nameReplacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// NameDayDate is a date of the name days of the name
+type NameDayDate struct {
+	Date
+	// the name as it is written in the report, it may be another form of the name looked up
+	Name      string
+	Tradition Confession
+}
+
+// NameDayIndex maps the names to the dates of their name days
+type NameDayIndex struct {
+	dates map[string][]NameDayDate
+	// the full and the church forms and the diminutives of the names by any of them
+	variants map[string][]string
+}
+
+func NewNameDayIndex() *NameDayIndex {
+	index := &NameDayIndex{dates: map[string][]NameDayDate{}, variants: map[string][]string{}}
+	for _, group := range nameVariants {
+		for _, name := range group {
+			key := normalizeName(name)
+			for _, variant := range group {
+				index.variants[key] = append(index.variants[key], normalizeName(variant))
+			}
+		}
+	}
+	return index
+}
+
+// Add puts the names of the report to the index, the flat NameDays are used for the reports without NameDayEntries
+func (index *NameDayIndex) Add(date Date, report *Report) {
+	entries := report.NameDayEntries
+	if len(entries) == 0 {
+		for _, name := range report.NameDays {
+			entries = append(entries, NameDay{Name: name})
+		}
+	}
+	for _, entry := range entries {
+		// "Сатурнин/Саторнин" is the name of the both spellings
+		for _, name := range strings.Split(entry.Name, "/") {
+			key := normalizeName(name)
+			if key == "" {
+				continue
+			}
+			dates := index.dates[key]
+			nameDate := NameDayDate{Date: date, Name: strings.TrimSpace(name), Tradition: entry.Tradition}
+			exists := false
+			for _, existed := range dates {
+				if existed == nameDate {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				index.dates[key] = append(dates, nameDate)
+			}
+		}
+	}
+}
+
+// NameDayIndex builds the index of all the days of the dataset
+func (dataset *Dataset) NameDayIndex() *NameDayIndex {
+	index := NewNameDayIndex()
+	for i := range dataset.days {
+		day := &dataset.days[i]
+		index.Add(Date{day.Month, day.Day}, &day.Report)
+	}
+	return index
+}
+
+// Lookup returns the name days of the name and its other forms ordered by date,
+// the forms of the same date and tradition are given once
+func (index *NameDayIndex) Lookup(name string) []NameDayDate {
+	key := normalizeName(name)
+	keys := []string{key}
+	for _, variant := range index.variants[key] {
+		if variant != key {
+			keys = append(keys, variant)
+		}
+	}
+	// a date of a tradition is given once, by the first of the forms which has it
+	type dateTradition struct {
+		date      Date
+		tradition Confession
+	}
+	var dates []NameDayDate
+	seen := map[dateTradition]bool{}
+	for _, key := range keys {
+		for _, date := range index.dates[key] {
+			if !seen[dateTradition{date.Date, date.Tradition}] {
+				seen[dateTradition{date.Date, date.Tradition}] = true
+				dates = append(dates, date)
+			}
+		}
+	}
+	sort.SliceStable(dates, func(i, j int) bool {
+		return dates[i].Date.before(dates[j].Date)
+	})
+	return dates
+}
+
+// Answer formats the name days of the name for the bot with the renderer of its parse mode,
+// the name is the user input and is escaped by the renderer, the answer fits a Telegram message
+func (index *NameDayIndex) Answer(renderer *MessageRenderer, name string) string {
+	return renderer.RenderNameDaysWith(name, index.Lookup(name), RenderOptions{MaxLength: TelegramMessageLimit})
+}
+
+func formatDate(date Date) string {
+	return strconv.Itoa(date.Day) + " " + monthsGenitive[date.Month-1]
+}
+
+// nameDaysLine is the line of the dates of a tradition, its dates can be trimmed to fit the message
+type nameDaysLine struct {
+	title string
+	dates []string
+	// visible lengths of the dates
+	lengths []int
+	shown   int
+}
+
+func (renderer *MessageRenderer) nameDaysTail(line *nameDaysLine) string {
+	rest := len(line.dates) - line.shown
+	if rest == 0 {
+		return ""
+	}
+	if line.shown > 0 {
+		return ", " + moreItems + strconv.Itoa(rest)
+	}
+	return moreItems + strconv.Itoa(rest)
+}
+
+func (renderer *MessageRenderer) nameDaysLine(line *nameDaysLine) string {
+	return line.title + renderer.item(strings.Join(line.dates[:line.shown], ", ")+renderer.nameDaysTail(line)) + "\n"
+}
+
+// RenderNameDays formats the answer to "when is my name day", the dates are grouped by tradition
+func (renderer *MessageRenderer) RenderNameDays(name string, dates []NameDayDate) string {
+	return renderer.RenderNameDaysWith(name, dates, RenderOptions{})
+}
+
+// RenderNameDaysWith formats the answer with at most MaxItems dates of a tradition,
+// the dates of the last traditions are trimmed first when the answer is over MaxLength
+func (renderer *MessageRenderer) RenderNameDaysWith(name string, dates []NameDayDate, options RenderOptions) string {
+	formattedStr := renderer.bold(nameDaysTitle+": "+strings.TrimSpace(name)) + "\n"
+	if len(dates) == 0 {
+		return formattedStr + renderer.escape(nameDaysNotFound) + "\n"
+	}
+	traditions := []Confession{ConfessionOrthodox, ConfessionCatholic, ""}
+	groups := map[Confession]*nameDaysLine{}
+	key := normalizeName(name)
+	for _, date := range dates {
+		tradition := date.Tradition
+		if tradition != ConfessionOrthodox && tradition != ConfessionCatholic {
+			tradition = ""
+		}
+		formatted := formatDate(date.Date)
+		if normalizeName(date.Name) != key {
+			formatted += " (" + date.Name + ")"
+		}
+		group, ok := groups[tradition]
+		if !ok {
+			group = &nameDaysLine{}
+			groups[tradition] = group
+		}
+		group.dates = append(group.dates, formatted)
+		group.lengths = append(group.lengths, renderer.visible(renderer.escape(formatted)))
+		group.shown++
+	}
+	var lines []*nameDaysLine
+	for _, tradition := range traditions {
+		group, ok := groups[tradition]
+		if !ok {
+			continue
+		}
+		// the snapshots before the traditions were kept have only the names
+		if len(groups) > 1 || tradition != "" {
+			title := otherTradition
+			if tradition != "" {
+				title = confessionTitles[tradition]
+			}
+			group.title = renderer.italic(title) + "\n"
+		}
+		if options.MaxItems > 0 && group.shown > options.MaxItems {
+			group.shown = options.MaxItems
+		}
+		lines = append(lines, group)
+	}
+
+	if options.MaxLength > 0 {
+		length := renderer.visible(formattedStr)
+		for _, line := range lines {
+			length += renderer.visible(renderer.nameDaysLine(line))
+		}
+		for i := len(lines) - 1; i >= 0 && length > options.MaxLength; i-- {
+			line := lines[i]
+			for line.shown > 0 && length > options.MaxLength {
+				tail := renderer.visible(renderer.escape(renderer.nameDaysTail(line)))
+				line.shown--
+				length -= line.lengths[line.shown]
+				if line.shown > 0 {
+					// the separator before the date
+					length -= 2
+				}
+				length += renderer.visible(renderer.escape(renderer.nameDaysTail(line))) - tail
+			}
+		}
+	}
+	for _, line := range lines {
+		formattedStr += renderer.nameDaysLine(line)
+	}
+	return formattedStr
+}
+
+// the groups of the forms of the same name, the diminutives are listed for the common names only
+var nameVariants = [][]string{
+	{"Александр", "Саша", "Шура", "Саня"},
+	{"Александра", "Саша", "Шура"},
+	{"Алексей", "Алёша", "Лёша"},
+	{"Анастасия", "Настя"},
+	{"Анатолий", "Толя"},
+	{"Андрей", "Андрюша"},
+	{"Анна", "Аня", "Нюра"},
+	{"Антон", "Антоний", "Антоша"},
+	{"Аркадий", "Аркаша"},
+	{"Борис", "Боря"},
+	{"Вадим", "Вадик"},
+	{"Валентин", "Валя"},
+	{"Валентина", "Валя"},
+	{"Валерий", "Валера"},
+	{"Варвара", "Варя"},
+	{"Василий", "Вася"},
+	{"Вера", "Верочка"},
+	{"Виктор", "Витя"},
+	{"Виталий", "Виталик"},
+	{"Владимир", "Володя", "Вова"},
+	{"Всеволод", "Сева"},
+	{"Галина", "Галя"},
+	{"Георгий", "Егор", "Юрий", "Жора", "Гоша", "Юра"},
+	{"Григорий", "Гриша"},
+	{"Дарья", "Дария", "Даша"},
+	{"Денис", "Дионисий", "Дениска"},
+	{"Дмитрий", "Димитрий", "Дима", "Митя"},
+	{"Евгений", "Женя"},
+	{"Евгения", "Женя"},
+	{"Екатерина", "Катя"},
+	{"Елена", "Лена"},
+	{"Елизавета", "Лиза"},
+	{"Ефим", "Евфимий"},
+	{"Иван", "Иоанн", "Ваня"},
+	{"Игорь", "Игорёк"},
+	{"Илья", "Илия", "Илюша"},
+	{"Ирина", "Ира"},
+	{"Константин", "Костя"},
+	{"Ксения", "Ксюша"},
+	{"Лариса", "Лара"},
+	{"Леонид", "Лёня"},
+	{"Любовь", "Люба"},
+	{"Людмила", "Люда", "Мила"},
+	{"Макар", "Макарий"},
+	{"Максим", "Макс"},
+	{"Маргарита", "Рита"},
+	{"Мария", "Маша", "Маруся"},
+	{"Михаил", "Миша"},
+	{"Надежда", "Надя"},
+	{"Наталья", "Наталия", "Наташа"},
+	{"Николай", "Коля"},
+	{"Нина", "Ниночка"},
+	{"Олег", "Олежка"},
+	{"Ольга", "Оля"},
+	{"Павел", "Паша"},
+	{"Пётр", "Петя"},
+	{"Роман", "Рома"},
+	{"Светлана", "Фотиния", "Света"},
+	{"Семён", "Симеон", "Сеня"},
+	{"Сергей", "Сергий", "Серёжа"},
+	{"Софья", "София", "Соня"},
+	{"Станислав", "Стас"},
+	{"Степан", "Стефан", "Стёпа"},
+	{"Тамара", "Тома"},
+	{"Татьяна", "Татиана", "Таня"},
+	{"Фёдор", "Феодор", "Федя"},
+	{"Юлия", "Иулия", "Юля"},
+	{"Яков", "Иаков", "Яша"},
+}
@@ -0,0 +1,231 @@
+package wiki
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_NameDayEntries(t *testing.T) {
+	page, err := testSource.GetPage("12 апреля")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Parse(page.Extract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var orthodox, catholic []string
+	for _, entry := range report.NameDayEntries {
+		switch entry.Tradition {
+		case ConfessionOrthodox:
+			orthodox = append(orthodox, entry.Name)
+		case ConfessionCatholic:
+			catholic = append(catholic, entry.Name)
+		default:
+			t.Error("Unexpected tradition:", entry)
+		}
+	}
+	expected := []string{"Виктор", "Зенон", "Людослав", "Юлиан"}
+	if !reflect.DeepEqual(expected, catholic) {
+		t.Error("Unexpected catholic names:", catholic)
+	}
+	if len(orthodox) != 11 || orthodox[3] != "Зенон" {
+		t.Error("Unexpected orthodox names:", orthodox)
+	}
+}
+
+func testNameDayIndex() *NameDayIndex {
+	index := NewNameDayIndex()
+	index.Add(Date{time.January, 20}, &Report{NameDayEntries: []NameDay{{Name: "Иоанн", Tradition: ConfessionOrthodox}}})
+	index.Add(Date{time.July, 7}, &Report{NameDayEntries: []NameDay{{Name: "Иван", Tradition: ConfessionOrthodox}, {Name: "Иван", Tradition: ConfessionCatholic}}})
+	index.Add(Date{time.July, 12}, &Report{NameDays: []string{"Пётр", "Сатурнин/Саторнин"}})
+	return index
+}
+
+func TestNameDayIndex_Lookup(t *testing.T) {
+	index := testNameDayIndex()
+	expected := []NameDayDate{
+		{Date{time.January, 20}, "Иоанн", ConfessionOrthodox},
+		{Date{time.July, 7}, "Иван", ConfessionOrthodox},
+		{Date{time.July, 7}, "Иван", ConfessionCatholic},
+	}
+	for _, name := range []string{"Иван", "ваня", " Иоанн"} {
+		if dates := index.Lookup(name); !reflect.DeepEqual(expected, dates) {
+			t.Error("Unexpected dates of", name, dates)
+		}
+	}
+	for _, name := range []string{"Петр", "петя", "Саторнин"} {
+		if dates := index.Lookup(name); len(dates) != 1 || dates[0].Date != (Date{time.July, 12}) {
+			t.Error("Unexpected dates of", name, dates)
+		}
+	}
+	if dates := index.Lookup("Ахиллес"); len(dates) != 0 {
+		t.Error("Expected no dates, got", dates)
+	}
+}
+
+func TestNameDayIndex_Answer(t *testing.T) {
+	index := testNameDayIndex()
+	expected := `*Именины: Ваня*
+_Православие_
+- 20 января (Иоанн), 7 июля (Иван)
+_Католицизм_
+- 7 июля (Иван)
+`
+	validateStrings(t, expected, index.Answer(NewLegacyMarkdownRenderer(), "Ваня"))
+	expected = `*Именины: Петя*
+- 12 июля (Пётр)
+`
+	validateStrings(t, expected, index.Answer(NewLegacyMarkdownRenderer(), "Петя"))
+	expected = `*Именины: Ахиллес*
+В календаре нет именин с этим именем
+`
+	validateStrings(t, expected, index.Answer(NewLegacyMarkdownRenderer(), "Ахиллес"))
+}
+
+func TestNameDayIndex_AnswerEscaped(t *testing.T) {
+	index := testNameDayIndex()
+	expected := `*Именины: Ро\*ман\_*
+В календаре нет именин с этим именем
+`
+	validateStrings(t, expected, index.Answer(NewMarkdownV2Renderer(), "Ро*ман_"))
+	expected = "*Именины: Ро\\[ман\\`\\]*\nВ календаре нет именин с этим именем\n"
+	validateStrings(t, expected, index.Answer(NewMarkdownV2Renderer(), "Ро[ман`]"))
+	expected = `*Именины: Ваня*
+_Православие_
+\- 20 января \(Иоанн\), 7 июля \(Иван\)
+_Католицизм_
+\- 7 июля \(Иван\)
+`
+	validateStrings(t, expected, index.Answer(NewMarkdownV2Renderer(), "Ваня"))
+	expected = `<b>Именины: Ро*ман_&lt;b&gt;</b>
+В календаре нет именин с этим именем
+`
+	validateStrings(t, expected, index.Answer(NewTelegramHTMLRenderer(), "Ро*ман_<b>"))
+}
+
+func TestNameDayIndex_AnswerLimit(t *testing.T) {
+	index := NewNameDayIndex()
+	// the forms of the name which share the date are answered once
+	index.Add(Date{time.March, 14}, &Report{NameDayEntries: []NameDay{
+		{Name: "Александр", Tradition: ConfessionOrthodox},
+		{Name: "Александра", Tradition: ConfessionOrthodox},
+	}})
+	if dates := index.Lookup("Саша"); len(dates) != 1 || dates[0].Name != "Александр" {
+		t.Error("Unexpected dates:", dates)
+	}
+
+	for month := time.January; month <= time.December; month++ {
+		for day := 1; day <= 28; day++ {
+			index.Add(Date{month, day}, &Report{NameDayEntries: []NameDay{{Name: "Александр", Tradition: ConfessionCatholic}}})
+		}
+	}
+	answer := index.Answer(NewLegacyMarkdownRenderer(), "Саша")
+	if length := NewLegacyMarkdownRenderer().visible(answer); length > TelegramMessageLimit || length < TelegramMessageLimit-40 {
+		t.Error("The answer must fill the message limit:", length)
+	}
+	if !strings.HasPrefix(answer, "*Именины: Саша*\n_Православие_\n- 14 марта (Александр)\n_Католицизм_\n") || !strings.Contains(answer, ", …и ещё ") {
+		t.Error("Unexpected answer:", answer)
+	}
+
+	expected := `*Именины: Саша*
+_Православие_
+- 14 марта (Александр)
+_Католицизм_
+- 1 января (Александр), 2 января (Александр), …и ещё 334
+`
+	validateStrings(t, expected, NewLegacyMarkdownRenderer().RenderNameDaysWith("Саша", index.Lookup("Саша"), RenderOptions{MaxItems: 2}))
+}
+
+func TestParse_NameDayGenderAndNote(t *testing.T) {
+	page, err := testSource.GetPage("15 августа")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Parse(page.Extract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := NameDay{Name: "Бон", Gender: GenderMale, Tradition: ConfessionOrthodox, Note: "мученик Бон", Style: NewStyle}
+	if report.NameDayEntries[1] != expected {
+		t.Error("Unexpected name day:", report.NameDayEntries[1])
+	}
+	female := 0
+	for _, entry := range report.NameDayEntries {
+		if entry.Gender == GenderFemale {
+			female++
+		}
+	}
+	if female == 0 {
+		t.Error("Expected female names in", report.NameDayEntries)
+	}
+}
+
+func TestReport_StringWithGroupNameDays(t *testing.T) {
+	report := Report{
+		NameDays: []string{"Зенон", "Иван", "Виктор"},
+		NameDayEntries: []NameDay{
+			{Name: "Зенон", Tradition: ConfessionOrthodox},
+			{Name: "Иван", Tradition: ConfessionOrthodox},
+			{Name: "Виктор", Tradition: ConfessionCatholic},
+			{Name: "Зенон", Tradition: ConfessionCatholic},
+		},
+	}
+	validateStrings(t, "\n_Именины_\n- Зенон, Иван, Виктор\n", report.String())
+	grouped := RenderOptions{GroupNameDays: true}
+	expected := `
+_Именины_
+- Православные: Зенон, Иван
+- Католические: Виктор, Зенон
+`
+	validateStrings(t, expected, report.StringWith(grouped))
+	expected = `
+<i>Именины</i>
+- Православные: Зенон, Иван
+- Католические: Виктор, Зенон
+`
+	validateStrings(t, expected, NewTelegramHTMLRenderer().RenderWith(&report, grouped))
+
+	report.NameDayEntries[0].Style = NewStyle
+	expected = `
+_Именины_
+- Православные (по новому стилю): Зенон, Иван
+- Католические: Виктор, Зенон
+`
+	validateStrings(t, expected, report.StringWith(grouped))
+}
+
+func TestParse_NameDayStyle(t *testing.T) {
+	report, err := Parse(`== Праздники ==
+=== Именины ===
+Православные (по старому стилю): Акакий, Арефа.
+Католические: Лаврентий.
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []NameDay{
+		{Name: "Акакий", Tradition: ConfessionOrthodox, Style: OldStyle},
+		{Name: "Арефа", Tradition: ConfessionOrthodox, Style: OldStyle},
+		{Name: "Лаврентий", Tradition: ConfessionCatholic},
+	}
+	if !reflect.DeepEqual(expected, report.NameDayEntries) {
+		t.Error("Unexpected name days:", report.NameDayEntries)
+	}
+
+	page, err := testSource.GetPage("10 августа")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = Parse(page.Extract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range report.NameDayEntries {
+		if entry.Tradition == ConfessionOrthodox && entry.Style != NewStyle {
+			t.Error("Unexpected style of", entry)
+		}
+	}
+}
@@ -3,11 +3,18 @@ package wiki
 import (
 	"bufio"
 	"errors"
-	"log"
 	"regexp"
 	"strings"
 )
 
+// the patterns are compiled once, confession specific ones live in ConfessionRules
+var (
+	extraLinkRegex      = regexp.MustCompile("Примечание: указано для невисокосных лет, в високосные годы список иной, см. \\d+ .*?\\.|\\(.*, см. \\d+ .*?\\)")
+	iconsRegex          = regexp.MustCompile("праздновани.*икон")
+	namedaysHeaderRegex = regexp.MustCompile("также:|Мужские:?|Женские:?|Католические:?|Православие:?|Православные( \\(?по (новому|старому) стилю\\)?)?( ?\\(старообрядцы\\))?:?|Дата (дана )?по (новому|старому) стилю:?|мученики:")
+	derivedNamesRegex   = regexp.MustCompile("и производные:")
+)
+
 type Parser struct {
 	report       *Report
 	header       string
@@ -15,29 +22,67 @@ type Parser struct {
 	currentArray *[]string
 	parser       func(line string)
 	currNames    []string
+	// tradition of the name days given by the last "Православные:" or "Католические:" marker
+	nameTradition Confession
+	// gender of the name days given by the last "Мужские:" or "Женские:" marker
+	nameGender Gender
+	// calendar of the orthodox name days given by the "по новому стилю" marker
+	nameStyle CalendarStyle
+	// the explanation after the dash of the current name days item
+	nameNote     string
 	skipNext     bool
+	lineNum      int
+	warnings     []Warning
+	holidaysLine int
+	omensLine    int
+	rules        *ConfessionRules
+	currentRule  *ConfessionRule
+	// countries named by a separate line like "Россия:" for the following national holidays
+	localCountries []string
+	history        *[]*HistoryGroup
+	historyGroup   *HistoryGroup
+	historyYear    int
+	// kind of the current subheader of the holidays section
+	subsection string
+	sections   *SectionRegistry
+	handler    SectionHandler
+	section    Section
 }
 
 func (parser *Parser) reset() {
 	parser.header = ""
 	parser.subheader = ""
+	parser.subsection = ""
 	parser.currentArray = nil
 	parser.parser = nil
 	parser.currNames = nil
+	parser.nameTradition = ""
+	parser.nameGender = ""
+	parser.nameStyle = ""
 	parser.skipNext = false
+	parser.currentRule = nil
+	parser.localCountries = nil
+	parser.history = nil
 }
 
 func (parser *Parser) setHeader(header string, parserFunc func(line string)) {
 	parser.header = header
 	parser.subheader = ""
+	parser.subsection = ""
 	parser.currentArray = nil
+	parser.currentRule = nil
+	parser.localCountries = nil
+	parser.history = nil
 	parser.parser = parserFunc
 }
 
 func (parser *Parser) setSubheader(subheader string) {
 	parser.subheader = strings.TrimSpace(subheader)
+	parser.subsection = parser.sections.subsection(parser.subheader)
 	parser.currentArray = nil
-	if parser.subheader == "Региональные" {
+	parser.currentRule = nil
+	parser.localCountries = nil
+	if parser.subsection == regSubsection {
 		parser.parser = parser.parseHolidays
 	}
 }
@@ -50,86 +95,54 @@ func (parser *Parser) parseHolidays(line string) {
 	if parser.subheader == "" {
 		parser.report.HolidaysInt = append(parser.report.HolidaysInt, line)
 		return
-	} else if parser.currentArray == nil && parser.subheader != rlgHolidaysSubheader {
-		switch parser.subheader {
-		case intHolidaysSubheader:
-			parser.currentArray = &parser.report.HolidaysInt
-		case "Мир":
+	} else if parser.currentArray == nil && parser.subsection != rlgSubsection {
+		switch parser.subsection {
+		case intSubsection:
 			parser.currentArray = &parser.report.HolidaysInt
-		case locHolidaysSubheader, regHolidaysSubheader:
+		case locSubsection, regSubsection:
 			parser.currentArray = &parser.report.HolidaysLoc
-		case profHolidaysSubheader:
+		case profSubsection:
 			parser.currentArray = &parser.report.HolidaysProf
-		case nameDaysSubheader:
+		case nameDaysSubsection:
 			parser.currNames = nil
+			parser.nameTradition = ""
+			parser.nameGender = ""
+			parser.nameStyle = ""
 			parser.parser = parser.parseNamedays
 			parser.parser(line)
 			return
 		default:
+			parser.warn(UnknownSubheader, parser.subheader)
 			parser.subheader = ""
+			parser.subsection = ""
 			return
 		}
-	} else if parser.subheader == rlgHolidaysSubheader {
+	} else if parser.subsection == rlgSubsection {
 		if line == "Христианские" {
 			return
 		}
-		extraLinkMatch := regexp.MustCompile("Примечание: указано для невисокосных лет, в високосные годы список иной, см. \\d+ .*?\\.|\\(.*, см. \\d+ .*?\\)")
-		orthRegex := regexp.MustCompile("Православ(ие|ные):?( (\\(|.*)Русская Православная Церковь(\\)|.*))?( ?\\(старообрядцы\\))?|В .*[Пп]равосл.* церкв(и|ях):?|(\\(|.*)Русская Православная Церковь(\\)|.*)")
-		cathRegex := regexp.MustCompile("Католи(цизм|ческие|чество)|В [Кк]атолич.* церко?в(ь|и|ях):?|(В )?([^-]|^)[Кк]атолич.* церко?в(ь|и|ях):?|Католици́зм или католи́чество")
-		othersRegex := regexp.MustCompile("Зороастризм|Другие конфессии|В католичестве и протестантстве|:?Славянские праздники:?|Ислам(ские|.?)|В Древневосточных церквях:?|Буддизм")
-		bahaiRegex := regexp.MustCompile("Бахаи(зм)?")
-		armRegex := regexp.MustCompile("Армянская апостольская церковь:?")
-		luterRg := regexp.MustCompile("Лютеранство:?")
-		heathenismRg := regexp.MustCompile("Язычество:?")
-		switch {
-		case extraLinkMatch.MatchString(line):
-			line = parser.splitLineWithHeader(extraLinkMatch, line, nil)
-		case orthRegex.MatchString(line):
-			newItem := ReligiousHolidayDescr{GroupAbbr: "правосл."}
-			parser.report.HolidaysRlg.Holidays = append(parser.report.HolidaysRlg.Holidays, &newItem)
-			line = parser.splitLineWithHeader(orthRegex, line, &newItem.Descriptions)
-		case cathRegex.MatchString(line):
-			newItem := ReligiousHolidayDescr{GroupAbbr: "катол."}
-			parser.report.HolidaysRlg.Holidays = append(parser.report.HolidaysRlg.Holidays, &newItem)
-			line = parser.splitLineWithHeader(cathRegex, line, &newItem.Descriptions)
-			parser.skipNext = false
-		case othersRegex.MatchString(line):
-			newItem := ReligiousHolidayDescr{}
-			parser.report.HolidaysRlg.Holidays = append(parser.report.HolidaysRlg.Holidays, &newItem)
-			line = parser.splitLineWithHeader(othersRegex, line, &newItem.Descriptions)
-		case bahaiRegex.MatchString(line):
-			newItem := ReligiousHolidayDescr{GroupAbbr: "бахаи"}
-			parser.report.HolidaysRlg.Holidays = append(parser.report.HolidaysRlg.Holidays, &newItem)
-			line = parser.splitLineWithHeader(bahaiRegex, line, &newItem.Descriptions)
-		case armRegex.MatchString(line):
-			newItem := ReligiousHolidayDescr{GroupAbbr: "Армянская апостол. церковь"}
+		if extraLinkRegex.MatchString(line) {
+			line = parser.splitLineWithHeader(extraLinkRegex, line, nil)
+		} else if rule := parser.rules.Match(line); rule != nil {
+			newItem := ReligiousHolidayDescr{GroupAbbr: rule.Abbr, Confession: rule.Id}
 			parser.report.HolidaysRlg.Holidays = append(parser.report.HolidaysRlg.Holidays, &newItem)
-			line = parser.splitLineWithHeader(armRegex, line, &newItem.Descriptions)
-		case luterRg.MatchString(line):
-			newItem := ReligiousHolidayDescr{GroupAbbr: "лютер"}
-			parser.report.HolidaysRlg.Holidays = append(parser.report.HolidaysRlg.Holidays, &newItem)
-			line = parser.splitLineWithHeader(luterRg, line, &newItem.Descriptions)
-		case heathenismRg.MatchString(line):
-			newItem := ReligiousHolidayDescr{GroupAbbr: "языч."}
-			parser.report.HolidaysRlg.Holidays = append(parser.report.HolidaysRlg.Holidays, &newItem)
-			line = parser.splitLineWithHeader(heathenismRg, line, &newItem.Descriptions)
-		case parser.currentArray == nil:
-			newItem := ReligiousHolidayDescr{}
+			parser.currentRule = rule
+			line = parser.splitLineWithHeader(rule.pattern, line, &newItem.Descriptions)
+			if rule.EndsIconList {
+				parser.skipNext = false
+			}
+		} else if parser.currentArray == nil {
+			newItem := ReligiousHolidayDescr{Confession: ConfessionOther}
 			parser.report.HolidaysRlg.Holidays = append(parser.report.HolidaysRlg.Holidays, &newItem)
+			parser.currentRule = nil
 			parser.currentArray = &newItem.Descriptions
 		}
-		//reApostle := regexp.MustCompile("память апостол.*")
-		reToExclude := regexp.MustCompile("^[Пп]амять .*|.*священномучени.*|.*мощей.*|.*преставление .*|Собор .*|.*переходящее празднование в.*|предпраздн.*")
 
-		if has := reToExclude.MatchString(line); has {
-			//if has = reApostle.MatchString(line); !has {
-			//	return
-			//}
+		if parser.rules.Excluded(parser.currentRule, line) {
 			return
 		}
 
-		reIcons := regexp.MustCompile("праздновани.*икон")
-		if has := reIcons.MatchString(line); has {
+		if has := iconsRegex.MatchString(line); has {
 			if strings.Contains(line, ":") {
 				parser.skipNext = true
 			}
@@ -137,7 +150,7 @@ func (parser *Parser) parseHolidays(line string) {
 		}
 	}
 	if parser.currentArray == nil {
-		log.Print("Error parsing:", line)
+		parser.warn(UnparsedLine, line)
 		return
 	}
 	if line == "" {
@@ -147,6 +160,9 @@ func (parser *Parser) parseHolidays(line string) {
 		return
 	}
 	*parser.currentArray = append(*parser.currentArray, line)
+	if parser.currentArray == &parser.report.HolidaysLoc {
+		parser.addLocalHoliday(line)
+	}
 }
 
 func (parser *Parser) splitLineWithHeader(headerRegexp *regexp.Regexp, line string, filled *[]string) string {
@@ -170,10 +186,12 @@ func (parser *Parser) splitLineWithHeader(headerRegexp *regexp.Regexp, line stri
 
 func (parser *Parser) parseNamedays(line string) {
 	line = strings.Trim(line, ".;— ")
-	reAs := regexp.MustCompile("также:|Мужские:?|Женские:?|Католические:?|Православие:?|Православные( \\(?по новому стилю\\)?)?( ?\\(старообрядцы\\))?:?|Дата (дана )?по новому стилю:?|мученики:")
-	if has := reAs.MatchString(line); has {
-		lines := reAs.Split(line, 2)
-		for _, l := range lines {
+	if has := namedaysHeaderRegex.MatchString(line); has {
+		lines := namedaysHeaderRegex.Split(line, 2)
+		for i, l := range lines {
+			if i == 1 {
+				parser.setNameMarker(namedaysHeaderRegex.FindString(line))
+			}
 			l = strings.TrimSpace(l)
 			if l != "" {
 				parser.parseSubnames(l)
@@ -181,9 +199,8 @@ func (parser *Parser) parseNamedays(line string) {
 		}
 		return
 	}
-	reAs = regexp.MustCompile("и производные:")
-	if has := reAs.MatchString(line); has {
-		line = reAs.Split(line, 2)[0]
+	if has := derivedNamesRegex.MatchString(line); has {
+		line = derivedNamesRegex.Split(line, 2)[0]
 	}
 	tline := strings.TrimSpace(line)
 	parser.parseSubnames(tline)
@@ -193,14 +210,19 @@ func (parser *Parser) parseSubnames(line string) {
 	switch {
 	case strings.Contains(line, "— ") && strings.Contains(line, ","), strings.Contains(line, "— ") && strings.Contains(line, " (") && strings.Contains(line, ")"):
 		s := strings.Split(line, "— ")
+		parser.nameNote = strings.TrimSpace(strings.Join(s[1:], "— "))
 		parser.parseSubnames(strings.TrimSpace(s[0]))
+		parser.nameNote = ""
 	default:
 		names := strings.Split(line, ",")
 		for _, name := range names {
 			name = strings.Trim(name, ":")
 			if strings.Contains(name, "— ") {
 				s := strings.Split(name, "— ")
+				note := parser.nameNote
+				parser.nameNote = strings.TrimSpace(strings.Join(s[1:], "— "))
 				parser.addName(strings.TrimSpace(s[0]))
+				parser.nameNote = note
 			} else {
 				parser.addName(strings.TrimSpace(name))
 			}
@@ -210,7 +232,8 @@ func (parser *Parser) parseSubnames(line string) {
 
 func (parser *Parser) addName(line string) {
 	var names []string
-	var namesToCheck []string
+	namesToCheck := nameCandidates(line)
+	parser.addNameEntries(namesToCheck)
 
 	for _, existedName := range parser.report.NameDays {
 		if strings.Contains(line, existedName) {
@@ -225,8 +248,27 @@ func (parser *Parser) addName(line string) {
 		}
 	}
 
+	for _, checkedName := range namesToCheck {
+		exists := false
+		for _, existedNames := range parser.report.NameDays {
+			if existedNames == checkedName || checkedName == "имя" {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			names = append(names, checkedName)
+		}
+	}
+
+	parser.appendNames(names)
+}
+
+// nameCandidates takes the names out of a single item of the name days list
+func nameCandidates(line string) []string {
+	var namesToCheck []string
 	if strings.Contains(line, "мощей") {
-		return
+		return nil
 	}
 	lines := strings.Split(line, " ")
 
@@ -248,27 +290,9 @@ func (parser *Parser) addName(line string) {
 	case 3:
 		if lines[1] == "и" {
 			namesToCheck = append(namesToCheck, lines[0], lines[2])
-			break
 		}
-		return
-	default:
-		return
 	}
-
-	for _, checkedName := range namesToCheck {
-		exists := false
-		for _, existedNames := range parser.report.NameDays {
-			if existedNames == checkedName || checkedName == "имя" {
-				exists = true
-				break
-			}
-		}
-		if !exists {
-			names = append(names, checkedName)
-		}
-	}
-
-	parser.appendNames(names)
+	return namesToCheck
 }
 
 func (parser *Parser) appendNames(line []string) {
@@ -280,6 +304,7 @@ func (parser *Parser) appendNames(line []string) {
 }
 
 func (parser *Parser) parseOmens(line string) {
+	parser.addFolkLine(line)
 	if parser.currentArray == nil {
 		parser.currentArray = &parser.report.Omens
 	}
@@ -329,44 +354,77 @@ func (parser *Parser) appendOmens(line string, firstLine bool) {
 	}
 }
 
+func (parser *Parser) beginSection(header string) {
+	parser.skipNext = false
+	parser.reset()
+	parser.handler = parser.sections.Handler(header)
+	if parser.handler == nil {
+		parser.warn(UnknownHeader, header)
+		return
+	}
+	parser.header = header
+	parser.section = Section{Header: header, Line: parser.lineNum, Report: parser.report, parser: parser}
+	parser.handler.Begin(&parser.section)
+}
+
+func (parser *Parser) subsectionHeader(level int, subheader string) {
+	if parser.handler == nil {
+		return
+	}
+	parser.section.Line = parser.lineNum
+	parser.section.Subheader = strings.TrimSpace(subheader)
+	parser.handler.Subheader(&parser.section, level, subheader)
+}
+
+func (parser *Parser) checkEmptySections() {
+	report := parser.report
+	if parser.holidaysLine > 0 && len(report.HolidaysInt) == 0 && len(report.HolidaysLoc) == 0 &&
+		len(report.HolidaysProf) == 0 && report.HolidaysRlg.Empty() && len(report.NameDays) == 0 {
+		parser.warnings = append(parser.warnings, Warning{Line: parser.holidaysLine, Header: holidaysHeader, Reason: EmptySection})
+	}
+	if parser.omensLine > 0 && len(report.Omens) == 0 {
+		parser.warnings = append(parser.warnings, Warning{Line: parser.omensLine, Header: "Приметы", Reason: EmptySection})
+	}
+}
+
 func Parse(fullReport string) (Report, error) {
-	report := Report{}
+	result, err := ParseWithWarnings(fullReport)
+	return result.Report, err
+}
+
+// ParseWithWarnings parses the article like Parse and also reports
+// the places where the layout of the article was not recognised
+func ParseWithWarnings(fullReport string) (ParseResult, error) {
+	result := ParseResult{}
 	if fullReport == "" {
-		return report, errors.New("empty report")
+		return result, errors.New("empty report")
 	}
 	scanner := bufio.NewScanner(strings.NewReader(fullReport))
-	parser := Parser{report: &report}
+	parser := Parser{report: &result.Report, rules: getConfessionRules(), sections: sections}
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		parser.lineNum++
 		switch {
 		case strings.HasPrefix(line, "== ") && strings.HasSuffix(line, " =="):
-			parser.skipNext = false
-			switch header := strings.TrimSpace(strings.Trim(line, "==")); header {
-			case holidaysHeader, "Праздники":
-				parser.setHeader(header, parser.parseHolidays)
-			case "События", "Родились", "Скончались":
-				parser.reset()
-			case "Приметы", "Народный календарь", "Народный календарь и приметы", "Народный календарь, приметы", "Народный календарь, приметы и фольклор Руси":
-				parser.setHeader(header, parser.parseOmens)
-			default:
-				parser.reset()
-				//log.Print("Extra header:", header)
-			}
+			parser.beginSection(strings.TrimSpace(strings.Trim(line, "==")))
 		case strings.HasPrefix(line, "=== ") && strings.HasSuffix(line, " ==="):
 			parser.skipNext = false
-			parser.setSubheader(strings.Trim(line, "==="))
+			parser.subsectionHeader(3, strings.Trim(line, "==="))
 		case strings.HasPrefix(line, "==== ") && strings.HasSuffix(line, " ===="):
 			parser.skipNext = false
-			parser.parser(strings.Trim(line, "===="))
+			parser.subsectionHeader(4, strings.Trim(line, "===="))
 		case line == "":
 			continue
 		default:
-			if parser.parser == nil {
+			if parser.handler == nil {
 				continue
 			}
-			parser.parser(strings.TrimSpace(line))
+			parser.section.Line = parser.lineNum
+			parser.handler.Line(&parser.section, line)
 		}
 	}
-	return report, nil
+	parser.checkEmptySections()
+	result.Warnings = parser.warnings
+	return result, nil
 }
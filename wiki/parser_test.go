@@ -19,13 +19,18 @@ func testParserByString(t *testing.T, fullReport string, expected string) {
 	validateStrings(t, expected, actualString)
 }
 
+var testSource PageSource = &DirSource{Dir: "testdata"}
+
 func testParserByDate(t *testing.T, month time.Month, day int, expected string) {
 	location, _ := time.LoadLocation("Europe/Moscow")
 	now := time.Date(2019, month, day, 1, 1, 1, 1, location)
-	fullReport := getWikiReport(&now)
-	fmt.Print(fullReport)
+	page, err := testSource.GetPage(getDateString(&now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Print(page.Extract)
 
-	report, _ := Parse(fullReport)
+	report, _ := Parse(page.Extract)
 	actualString := report.String()
 	validateStrings(t, expected, actualString)
 }
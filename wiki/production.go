@@ -0,0 +1,200 @@
+package wiki
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DayKind is the kind of the day in the Russian production calendar
+type DayKind string
+
+const (
+	WorkingDay DayKind = "working"
+	// the weekends and the days off transferred to the weekdays
+	Weekend       DayKind = "weekend"
+	PublicHoliday DayKind = "holiday"
+	// the working day before a public holiday, one hour shorter
+	ShortenedDay DayKind = "shortened"
+)
+
+var dayKindTitles = map[DayKind]string{
+	WorkingDay:    "Рабочий день",
+	Weekend:       "Выходной день",
+	PublicHoliday: "Нерабочий праздничный день",
+	ShortenedDay:  "Сокращённый предпраздничный день",
+}
+
+func (kind DayKind) DayOff() bool {
+	return kind == Weekend || kind == PublicHoliday
+}
+
+// the year of the calendar file, the dates are "MM-DD"
+type productionYear struct {
+	Holidays []string `json:"holidays"`
+	// the weekdays which are days off after the transfers
+	DaysOff []string `json:"daysOff"`
+	// the weekends which are working days after the transfers
+	Workdays  []string `json:"workdays"`
+	Shortened []string `json:"shortened"`
+}
+
+// ProductionCalendar tells the working days of the years of the official calendar,
+// the days which are not listed are the working weekdays and the weekends
+type ProductionCalendar struct {
+	sync.RWMutex
+	// the days which differ from the plain week
+	years map[int]map[Date]DayKind
+}
+
+//go:generate go run gen_data.go
+
+// NewProductionCalendar returns the calendar of the years of production_calendar.json built into the library
+func NewProductionCalendar() *ProductionCalendar {
+	calendar := &ProductionCalendar{years: map[int]map[Date]DayKind{}}
+	if err := calendar.Load(strings.NewReader(defaultProductionCalendar)); err != nil {
+		panic(err)
+	}
+	return calendar
+}
+
+func parseCalendarDate(year int, value string) (Date, error) {
+	day, err := time.Parse("01-02", value)
+	if err != nil {
+		return Date{}, errors.New("invalid date of the production calendar: " + value)
+	}
+	if year%4 != 0 && day.Month() == time.February && day.Day() == 29 {
+		return Date{}, errors.New("invalid date of the production calendar: " + value)
+	}
+	return Date{day.Month(), day.Day()}, nil
+}
+
+// Load reads the years of the calendar file, the loaded years replace the known ones
+func (calendar *ProductionCalendar) Load(reader io.Reader) error {
+	var file map[string]productionYear
+	if err := json.NewDecoder(reader).Decode(&file); err != nil {
+		return err
+	}
+	years := map[int]map[Date]DayKind{}
+	for key, data := range file {
+		year, err := strconv.Atoi(key)
+		if err != nil {
+			return errors.New("invalid year of the production calendar: " + key)
+		}
+		days := map[Date]DayKind{}
+		// the later lists win: a shortened day may be a transferred working weekend
+		for _, list := range []struct {
+			kind  DayKind
+			dates []string
+		}{
+			{PublicHoliday, data.Holidays},
+			{Weekend, data.DaysOff},
+			{WorkingDay, data.Workdays},
+			{ShortenedDay, data.Shortened},
+		} {
+			for _, value := range list.dates {
+				date, err := parseCalendarDate(year, value)
+				if err != nil {
+					return err
+				}
+				days[date] = list.kind
+			}
+		}
+		years[year] = days
+	}
+	calendar.Lock()
+	defer calendar.Unlock()
+	for year, days := range years {
+		calendar.years[year] = days
+	}
+	return nil
+}
+
+// Kind returns the kind of the day, false when the calendar has no data for the year
+func (calendar *ProductionCalendar) Kind(day time.Time) (DayKind, bool) {
+	calendar.RLock()
+	defer calendar.RUnlock()
+	return calendar.kind(day)
+}
+
+func (calendar *ProductionCalendar) kind(day time.Time) (DayKind, bool) {
+	days, ok := calendar.years[day.Year()]
+	if !ok {
+		return "", false
+	}
+	if kind, ok := days[Date{day.Month(), day.Day()}]; ok {
+		return kind, true
+	}
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return Weekend, true
+	}
+	return WorkingDay, true
+}
+
+// WorkingDaysLeft counts the working days after the day till the end of its month and year
+func (calendar *ProductionCalendar) WorkingDaysLeft(day time.Time) (month int, year int, ok bool) {
+	calendar.RLock()
+	defer calendar.RUnlock()
+	if _, ok := calendar.years[day.Year()]; !ok {
+		return 0, 0, false
+	}
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	for next := date.AddDate(0, 0, 1); next.Year() == date.Year(); next = next.AddDate(0, 0, 1) {
+		if kind, _ := calendar.kind(next); !kind.DayOff() {
+			year++
+			if next.Month() == date.Month() {
+				month++
+			}
+		}
+	}
+	return month, year, true
+}
+
+var productionCalendar = NewProductionCalendar()
+
+// LoadProductionCalendar overrides the years of the built-in calendar used by the stats with CalendarStatsOptions.ProductionCalendar,
+// e.g. with the decree of the next year before the library is released with it
+func LoadProductionCalendar(reader io.Reader) error {
+	return productionCalendar.Load(reader)
+}
+
+func LoadProductionCalendarFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return LoadProductionCalendar(file)
+}
+
+func workingDaysString(days int) string {
+	noun := GetDayNoun(days)
+	if noun == "день" {
+		return strconv.Itoa(days) + " рабочий " + noun
+	}
+	return strconv.Itoa(days) + " рабочих " + noun
+}
+
+// productionStats are the lines of the production calendar, empty when the year is unknown
+func productionStats(reportDay *time.Time) string {
+	kind, ok := productionCalendar.Kind(*reportDay)
+	if !ok {
+		return ""
+	}
+	firstLine := dayKindTitles[kind]
+	if tomorrow, ok := productionCalendar.Kind(reportDay.AddDate(0, 0, 1)); ok {
+		if tomorrow.DayOff() {
+			firstLine += ". Завтра выходной"
+		} else {
+			firstLine += ". Завтра рабочий день"
+		}
+	}
+	month, year, _ := productionCalendar.WorkingDaysLeft(*reportDay)
+	secondLine := "До конца месяца " + workingDaysString(month) + ", до конца года " + workingDaysString(year)
+	return firstLine + "\n" + secondLine + "\n"
+}
@@ -0,0 +1,26 @@
+// Code generated by gen_data.go from production_calendar.json; DO NOT EDIT.
+
+package wiki
+
+// the official calendars of the government decrees on the transfers of the days off
+const defaultProductionCalendar = `{
+ "2024": {
+  "holidays": ["01-01", "01-02", "01-03", "01-04", "01-05", "01-06", "01-07", "01-08", "02-23", "03-08", "05-01", "05-09", "06-12", "11-04"],
+  "daysOff": ["04-29", "04-30", "05-10", "12-30", "12-31"],
+  "workdays": ["04-27", "11-02", "12-28"],
+  "shortened": ["02-22", "03-07", "05-08", "06-11", "11-02"]
+ },
+ "2025": {
+  "holidays": ["01-01", "01-02", "01-03", "01-04", "01-05", "01-06", "01-07", "01-08", "02-23", "03-08", "05-01", "05-09", "06-12", "11-04"],
+  "daysOff": ["05-02", "05-08", "06-13", "11-03", "12-31"],
+  "workdays": ["11-01"],
+  "shortened": ["03-07", "04-30", "06-11", "11-01"]
+ },
+ "2026": {
+  "holidays": ["01-01", "01-02", "01-03", "01-04", "01-05", "01-06", "01-07", "01-08", "02-23", "03-08", "05-01", "05-09", "06-12", "11-04"],
+  "daysOff": ["01-09", "03-09", "05-11", "12-31"],
+  "workdays": [],
+  "shortened": ["04-30", "05-08", "06-11", "11-03"]
+ }
+}
+`
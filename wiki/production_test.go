@@ -0,0 +1,102 @@
+package wiki
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProductionCalendar_Generated(t *testing.T) {
+	contents, err := ioutil.ReadFile("../production_calendar.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != defaultProductionCalendar {
+		t.Error("production_calendar.go is out of date, run go generate ./wiki")
+	}
+}
+
+func TestProductionCalendar_Kind(t *testing.T) {
+	calendar := NewProductionCalendar()
+	for _, test := range []struct {
+		day  time.Time
+		kind DayKind
+	}{
+		{time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), PublicHoliday},
+		{time.Date(2024, time.April, 27, 0, 0, 0, 0, time.UTC), WorkingDay},
+		{time.Date(2024, time.April, 29, 0, 0, 0, 0, time.UTC), Weekend},
+		{time.Date(2024, time.November, 2, 0, 0, 0, 0, time.UTC), ShortenedDay},
+		{time.Date(2025, time.March, 8, 0, 0, 0, 0, time.UTC), PublicHoliday},
+		{time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC), WorkingDay},
+		{time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC), Weekend},
+		{time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC), WorkingDay},
+	} {
+		if kind, ok := calendar.Kind(test.day); !ok || kind != test.kind {
+			t.Error("Unexpected kind of", test.day.Format("2006-01-02"), kind, ok)
+		}
+	}
+	if _, ok := calendar.Kind(time.Date(2019, time.December, 1, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("Unexpected kind of the unknown year")
+	}
+}
+
+func TestProductionCalendar_WorkingDaysLeft(t *testing.T) {
+	calendar := NewProductionCalendar()
+	// the norms of the years: 248, 247 and 247 working days
+	for year, expected := range map[int]int{2024: 248, 2025: 247, 2026: 247} {
+		_, left, ok := calendar.WorkingDaysLeft(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC))
+		if !ok || left != expected {
+			t.Error("Unexpected working days of", year, left, ok)
+		}
+	}
+	month, year, _ := calendar.WorkingDaysLeft(time.Date(2025, time.December, 26, 0, 0, 0, 0, time.UTC))
+	if month != 2 || year != 2 {
+		t.Error("Unexpected working days left:", month, year)
+	}
+}
+
+func TestProductionCalendar_Load(t *testing.T) {
+	calendar := NewProductionCalendar()
+	err := calendar.Load(strings.NewReader(`{"2027": {"holidays": ["01-01"], "daysOff": ["01-11"], "workdays": ["01-09"], "shortened": ["12-30"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for day, expected := range map[int]DayKind{1: PublicHoliday, 2: Weekend, 9: WorkingDay, 11: Weekend, 12: WorkingDay} {
+		if kind, _ := calendar.Kind(time.Date(2027, time.January, day, 0, 0, 0, 0, time.UTC)); kind != expected {
+			t.Error("Unexpected kind of", day, "January:", kind)
+		}
+	}
+	if _, ok := calendar.Kind(time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC)); !ok {
+		t.Error("The loaded year replaced the other ones")
+	}
+	if err := calendar.Load(strings.NewReader(`{"2027": {"holidays": ["13-01"]}}`)); err == nil {
+		t.Error("Expected an error for the invalid date")
+	}
+}
+
+func TestGenerateCalendarStats_Production(t *testing.T) {
+	// the built-in calendar answers without any file loaded
+	options := CalendarStatsOptions{ProductionCalendar: true}
+	day := time.Date(2026, time.May, 8, 12, 0, 0, 0, time.UTC)
+	expected := `*Пятница, 8 мая 2026 года*
+128-й день года. До конца года 237 дней
+Сокращённый предпраздничный день. Завтра выходной
+До конца месяца 14 рабочих дней, до конца года 165 рабочих дней
+`
+	validateStrings(t, expected, GenerateCalendarStatsWith(&day, options))
+
+	day = time.Date(2026, time.December, 30, 12, 0, 0, 0, time.UTC)
+	expected = `*Среда, 30 декабря 2026 года*
+364-й день года. До конца года 1 день
+Рабочий день. Завтра выходной
+До конца месяца 0 рабочих дней, до конца года 0 рабочих дней
+`
+	validateStrings(t, expected, GenerateCalendarStatsWith(&day, options))
+
+	// the production calendar is an option
+	expected = `*Среда, 30 декабря 2026 года*
+364-й день года. До конца года 1 день
+`
+	validateStrings(t, expected, GenerateCalendarStats(&day))
+}
@@ -0,0 +1,467 @@
+package wiki
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const omensHeader = "Приметы"
+
+// the lines of Report.Omens in the legacy message, the title of the day included
+const legacyOmens = 5
+
+// the tail of the trimmed section: "…и ещё 3"
+const moreItems = "…и ещё "
+
+// TelegramMessageLimit is the longest text of a Telegram message
+const TelegramMessageLimit = 4096
+
+// RenderSection is a part of the message which can be selected, limited and moved to the next message
+type RenderSection string
+
+const (
+	SectionStats         RenderSection = "stats"
+	SectionInternational RenderSection = "international"
+	SectionNational      RenderSection = "national"
+	SectionProfessional  RenderSection = "professional"
+	SectionReligious     RenderSection = "religious"
+	SectionNameDays      RenderSection = "namedays"
+	SectionOmens         RenderSection = "omens"
+	// random events of the day, rendered only when RenderOptions.HistoryEvents is set
+	SectionHistory RenderSection = "history"
+)
+
+// the lines of the first sections are trimmed first when the message is over the budget,
+// the stats have no lines and are always kept
+var trimPriority = []RenderSection{
+	SectionHistory,
+	SectionOmens,
+	SectionNameDays,
+	SectionProfessional,
+	SectionNational,
+	SectionReligious,
+	SectionInternational,
+}
+
+// RenderOptions choose the sections of the message and shorten them
+type RenderOptions struct {
+	// Sections to render, all of them when empty
+	Sections []RenderSection
+	// MaxItems caps the lines of every section, 0 is no limit
+	MaxItems int
+	// MaxLength is the budget of a message in the characters the user sees, the markup and the escapes
+	// are not counted as Telegram does not count them in its limit; 0 is no limit
+	MaxLength int
+	// HistoryEvents is the number of the random events of "this day in history", 0 leaves the section out
+	HistoryEvents int
+	// Random picks the events, a generator seeded by the current time when nil
+	Random *rand.Rand
+	// GroupNameDays lists the name days by tradition when the article tells the traditions
+	GroupNameDays bool
+	// SplitFolkDay writes the names of the day, the omens and the sayings of Report.FolkDay
+	// with the legacy markdown renderer too, the other renderers always do
+	SplitFolkDay bool
+}
+
+func (options *RenderOptions) includes(section RenderSection) bool {
+	if len(options.Sections) == 0 {
+		return true
+	}
+	for _, selected := range options.Sections {
+		if selected == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Renderer formats the report for a message or a page
+type Renderer interface {
+	Render(report *Report) string
+}
+
+// MessageRenderer writes the report as a Telegram message, the layout is the same for all the parse modes
+type MessageRenderer struct {
+	escape func(text string) string
+	// wrap the escaped text
+	boldMarkup   func(text string) string
+	italicMarkup func(text string) string
+	// visible counts the characters of the rendered text the user sees, Telegram does not count the markup in its limit
+	visible func(text string) int
+	// flatOmens writes the first lines of Report.Omens as Report.String always did
+	flatOmens bool
+}
+
+func markdownBold(text string) string {
+	return "*" + text + "*"
+}
+
+func markdownItalic(text string) string {
+	return "_" + text + "_"
+}
+
+// NewLegacyMarkdownRenderer returns the renderer of the legacy Telegram Markdown without any escaping,
+// the omens are the first lines of the section unless RenderOptions.SplitFolkDay
+func NewLegacyMarkdownRenderer() *MessageRenderer {
+	return &MessageRenderer{
+		escape:       func(text string) string { return text },
+		boldMarkup:   markdownBold,
+		italicMarkup: markdownItalic,
+		visible:      legacyMarkdownLength,
+		flatOmens:    true,
+	}
+}
+
+// NewMarkdownV2Renderer returns the renderer of the Telegram MarkdownV2
+func NewMarkdownV2Renderer() *MessageRenderer {
+	return &MessageRenderer{
+		escape:       EscapeMarkdownV2,
+		boldMarkup:   markdownBold,
+		italicMarkup: markdownItalic,
+		visible:      markdownV2Length,
+	}
+}
+
+var markdownV2Replacer = strings.NewReplacer(
+	"\\", "\\\\",
+	"_", "\\_",
+	"*", "\\*",
+	"[", "\\[",
+	"]", "\\]",
+	"(", "\\(",
+	")", "\\)",
+	"~", "\\~",
+	"`", "\\`",
+	">", "\\>",
+	"#", "\\#",
+	"+", "\\+",
+	"-", "\\-",
+	"=", "\\=",
+	"|", "\\|",
+	"{", "\\{",
+	"}", "\\}",
+	".", "\\.",
+	"!", "\\!",
+)
+
+// EscapeMarkdownV2 escapes all the characters reserved by the Telegram MarkdownV2
+func EscapeMarkdownV2(text string) string {
+	return markdownV2Replacer.Replace(text)
+}
+
+func (renderer *MessageRenderer) bold(text string) string {
+	return renderer.boldMarkup(renderer.escape(text))
+}
+
+func (renderer *MessageRenderer) italic(text string) string {
+	return renderer.italicMarkup(renderer.escape(text))
+}
+
+func (renderer *MessageRenderer) item(text string) string {
+	return renderer.escape("- " + text)
+}
+
+// stats are generated with the bold lines already marked
+func (renderer *MessageRenderer) stats(stats string) string {
+	lines := strings.Split(stats, "\n")
+	for i, line := range lines {
+		if len(line) > 1 && strings.HasPrefix(line, "*") && strings.HasSuffix(line, "*") {
+			lines[i] = renderer.bold(strings.Trim(line, "*"))
+		} else {
+			lines[i] = renderer.escape(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownV2Length counts the characters of the MarkdownV2 text the user sees:
+// the bold and italic markup is left out and an escaped character is counted once
+func markdownV2Length(text string) int {
+	length := 0
+	escaped := false
+	for _, r := range text {
+		switch {
+		case escaped:
+			length++
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*' || r == '_':
+		default:
+			length++
+		}
+	}
+	return length
+}
+
+// legacyMarkdownLength counts the characters of the legacy markdown text the user sees,
+// the text is not escaped and the renderer marks only the whole lines,
+// so the literal "*" and "_" inside the lines are counted
+func legacyMarkdownLength(text string) int {
+	lines := strings.Split(text, "\n")
+	length := len(lines) - 1
+	for _, line := range lines {
+		length += utf8.RuneCountInString(line)
+		if len(line) > 1 && (line[0] == '*' || line[0] == '_') && line[len(line)-1] == line[0] {
+			length -= 2
+		}
+	}
+	return length
+}
+
+// messageBlock is a rendered section, its lines can be trimmed to fit the message
+type messageBlock struct {
+	section RenderSection
+	head    string
+	lines   []string
+	// visible lengths of the lines
+	lengths []int
+	shown   int
+	// tail replaces the trimmed lines
+	tail func(rest int) string
+	// visible is the length function of the renderer
+	visible func(text string) int
+}
+
+// tailLength is the visible length of the tail of the trimmed lines
+func (block *messageBlock) tailLength() int {
+	if rest := len(block.lines) - block.shown; rest > 0 {
+		return block.visible(block.tail(rest))
+	}
+	return 0
+}
+
+func (block *messageBlock) String() string {
+	text := block.head + strings.Join(block.lines[:block.shown], "")
+	if rest := len(block.lines) - block.shown; rest > 0 {
+		text += block.tail(rest)
+	}
+	return text
+}
+
+func (block *messageBlock) holidays() bool {
+	switch block.section {
+	case SectionInternational, SectionNational, SectionProfessional, SectionReligious:
+		return true
+	}
+	return false
+}
+
+func (renderer *MessageRenderer) newBlock(section RenderSection, head string) *messageBlock {
+	return &messageBlock{section: section, head: head, visible: renderer.visible, tail: func(rest int) string {
+		return renderer.escape(moreItems+strconv.Itoa(rest)) + "\n"
+	}}
+}
+
+func (block *messageBlock) add(line string) {
+	block.lines = append(block.lines, line+"\n")
+	block.lengths = append(block.lengths, block.visible(line)+1)
+	block.shown++
+}
+
+func (renderer *MessageRenderer) listBlock(section RenderSection, subheader string, lines []string) *messageBlock {
+	block := renderer.newBlock(section, "\n"+renderer.italic(subheader)+"\n")
+	for _, line := range lines {
+		block.add(renderer.item(line))
+	}
+	return block
+}
+
+// blocks renders the sections of the report in the message order
+func (renderer *MessageRenderer) blocks(report *Report, options *RenderOptions) []*messageBlock {
+	var blocks []*messageBlock
+	if report.Stats != "" {
+		blocks = append(blocks, renderer.newBlock(SectionStats, renderer.stats(report.Stats)+"\n"))
+	}
+	if len(report.HolidaysInt) > 0 {
+		blocks = append(blocks, renderer.listBlock(SectionInternational, intHolidaysSubheader, report.HolidaysInt))
+	}
+	if len(report.HolidaysLoc) > 0 {
+		blocks = append(blocks, renderer.listBlock(SectionNational, locHolidaysSubheader, report.HolidaysLoc))
+	}
+	if len(report.HolidaysProf) > 0 {
+		blocks = append(blocks, renderer.listBlock(SectionProfessional, profHolidaysSubheader, report.HolidaysProf))
+	}
+	if !report.HolidaysRlg.Empty() {
+		block := renderer.newBlock(SectionReligious, "\n"+renderer.italic(rlgHolidaysSubheader)+"\n")
+		for _, items := range report.HolidaysRlg.Holidays {
+			for _, line := range items.Descriptions {
+				if items.GroupAbbr != "" {
+					line += " (" + items.GroupAbbr + ")"
+				}
+				block.add(renderer.item(line))
+			}
+		}
+		blocks = append(blocks, block)
+	}
+
+	if options.GroupNameDays && report.hasNameTraditions() {
+		block := renderer.newBlock(SectionNameDays, "\n"+renderer.italic(nameDaysSubheader)+"\n")
+		for _, group := range report.nameDaysByTradition() {
+			line := strings.Join(group.names, ", ")
+			if title, ok := nameTraditionTitles[group.tradition]; ok {
+				if style, ok := calendarStyleTitles[group.style]; ok {
+					title += " (" + style + ")"
+				}
+				line = title + ": " + line
+			}
+			block.add(renderer.item(line))
+		}
+		blocks = append(blocks, block)
+	} else if len(report.NameDays) > 0 {
+		block := renderer.newBlock(SectionNameDays, "\n"+renderer.italic(nameDaysSubheader)+"\n")
+		var lines []string
+		extend := false
+		for _, line := range report.NameDays {
+			if strings.Contains(line, ":") {
+				lines = append(lines, renderer.item(line))
+				extend = false
+			} else {
+				if extend {
+					lines[len(lines)-1] += renderer.escape(", " + line)
+				} else {
+					lines = append(lines, renderer.item(line))
+					extend = true
+				}
+			}
+		}
+		for _, line := range lines {
+			block.add(line)
+		}
+		blocks = append(blocks, block)
+	}
+
+	if options.SplitFolkDay || !renderer.flatOmens {
+		// the customs are too long for a message, they are left for the web page
+		if folk := report.folkDay(); len(folk.Names) > 0 || len(folk.Omens) > 0 || len(folk.Sayings) > 0 {
+			block := renderer.newBlock(SectionOmens, "\n"+renderer.bold(omensHeader)+"\n\n")
+			if len(folk.Names) > 0 {
+				block.head += renderer.italic(strings.Join(folk.Names, ", ")) + "\n"
+			}
+			for _, line := range folk.Omens {
+				block.add(renderer.escape(line))
+			}
+			for _, line := range folk.Sayings {
+				block.add(renderer.escape(line))
+			}
+			blocks = append(blocks, block)
+		}
+	} else if len(report.Omens) > 0 {
+		// the first line is the title of the day and four more lines follow it
+		block := renderer.newBlock(SectionOmens, "\n"+renderer.bold(omensHeader)+"\n\n"+renderer.italic(report.Omens[0])+"\n")
+		for i, line := range report.Omens[1:] {
+			if i == legacyOmens-1 {
+				break
+			}
+			block.add(renderer.escape(line))
+		}
+		blocks = append(blocks, block)
+	}
+
+	if options.HistoryEvents > 0 {
+		random := options.Random
+		if random == nil {
+			random = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		if entries := report.RandomEvents(options.HistoryEvents, random); len(entries) > 0 {
+			block := renderer.newBlock(SectionHistory, "\n"+renderer.bold(historyHeader)+"\n")
+			for _, entry := range entries {
+				block.add(renderer.item(formatYear(entry.Year) + " — " + entry.Text))
+			}
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// join writes the blocks as one message, the holidays header goes before the first holiday section
+func (renderer *MessageRenderer) join(blocks []*messageBlock) string {
+	formattedStr := ""
+	holidays := false
+	for _, block := range blocks {
+		if block.holidays() && !holidays {
+			formattedStr += renderer.bold(holidaysHeader) + "\n"
+		}
+		holidays = block.holidays()
+		formattedStr += block.String()
+	}
+	return formattedStr
+}
+
+// fit trims the lines of the blocks by priority until the message is within the budget,
+// the headers are never trimmed so the message may stay longer than a very small budget
+func (renderer *MessageRenderer) fit(blocks []*messageBlock, budget int) {
+	if budget <= 0 {
+		return
+	}
+	// the headers do not change with the trimmed lines, so the length is only corrected by the lines and the tails
+	length := renderer.visible(renderer.join(blocks))
+	for _, section := range trimPriority {
+		for _, block := range blocks {
+			if block.section != section {
+				continue
+			}
+			for block.shown > 0 && length > budget {
+				tail := block.tailLength()
+				block.shown--
+				length += block.tailLength() - tail - block.lengths[block.shown]
+			}
+		}
+	}
+}
+
+// selected renders the blocks of the sections chosen by the options with at most MaxItems lines
+func (renderer *MessageRenderer) selected(report *Report, options *RenderOptions) []*messageBlock {
+	var blocks []*messageBlock
+	for _, block := range renderer.blocks(report, options) {
+		if !options.includes(block.section) {
+			continue
+		}
+		if options.MaxItems > 0 && block.shown > options.MaxItems {
+			block.shown = options.MaxItems
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func (renderer *MessageRenderer) Render(report *Report) string {
+	return renderer.RenderWith(report, RenderOptions{})
+}
+
+// RenderWith formats the report as one message shortened by the options
+func (renderer *MessageRenderer) RenderWith(report *Report, options RenderOptions) string {
+	blocks := renderer.selected(report, &options)
+	renderer.fit(blocks, options.MaxLength)
+	return renderer.join(blocks)
+}
+
+// RenderMessages splits the report into the messages of at most MaxLength characters at the section boundaries,
+// a section longer than the budget on its own is trimmed
+func (renderer *MessageRenderer) RenderMessages(report *Report, options RenderOptions) []string {
+	blocks := renderer.selected(report, &options)
+	if len(blocks) == 0 {
+		return nil
+	}
+	if options.MaxLength <= 0 {
+		return []string{renderer.join(blocks)}
+	}
+	var messages []string
+	var message []*messageBlock
+	flush := func() {
+		renderer.fit(message, options.MaxLength)
+		messages = append(messages, strings.TrimLeft(renderer.join(message), "\n"))
+		message = nil
+	}
+	for _, block := range blocks {
+		if len(message) > 0 && renderer.visible(renderer.join(append(message, block))) > options.MaxLength {
+			flush()
+		}
+		message = append(message, block)
+	}
+	flush()
+	return messages
+}
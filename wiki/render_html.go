@@ -0,0 +1,146 @@
+package wiki
+
+import (
+	"html"
+	"strings"
+)
+
+// full names of the confessions for the abbreviations
+var confessionTitles = map[Confession]string{
+	ConfessionOrthodox:         "Православие",
+	ConfessionCatholic:         "Католицизм",
+	ConfessionLutheran:         "Лютеранство",
+	ConfessionArmenian:         "Армянская апостольская церковь",
+	ConfessionOrientalOrthodox: "Древневосточные церкви",
+	ConfessionBahai:            "Бахаизм",
+	ConfessionIslam:            "Ислам",
+	ConfessionBuddhism:         "Буддизм",
+	ConfessionZoroastrianism:   "Зороастризм",
+	ConfessionSlavicPagan:      "Славянское язычество",
+	ConfessionPaganism:         "Язычество",
+}
+
+// NewTelegramHTMLRenderer returns the renderer for the Telegram HTML parse mode,
+// it supports only the inline tags so the layout is the one of the markdown renderers
+func NewTelegramHTMLRenderer() *MessageRenderer {
+	return &MessageRenderer{
+		escape:       html.EscapeString,
+		boldMarkup:   func(text string) string { return "<b>" + text + "</b>" },
+		italicMarkup: func(text string) string { return "<i>" + text + "</i>" },
+		visible:      telegramHTMLLength,
+	}
+}
+
+// telegramHTMLLength counts the characters of the Telegram HTML text the user sees:
+// the tags are left out and an entity is counted once
+func telegramHTMLLength(text string) int {
+	length := 0
+	tag, entity := false, false
+	for _, r := range text {
+		switch {
+		case tag:
+			tag = r != '>'
+		case entity:
+			entity = r != ';'
+		case r == '<':
+			tag = true
+		case r == '&':
+			entity = true
+			length++
+		default:
+			length++
+		}
+	}
+	return length
+}
+
+// HTMLRenderer writes the report as a fragment of the web page
+type HTMLRenderer struct{}
+
+func NewHTMLRenderer() *HTMLRenderer {
+	return &HTMLRenderer{}
+}
+
+func (renderer *HTMLRenderer) list(items []string) string {
+	formattedStr := "<ul>\n"
+	for _, item := range items {
+		formattedStr += "<li>" + html.EscapeString(item) + "</li>\n"
+	}
+	return formattedStr + "</ul>\n"
+}
+
+func (renderer *HTMLRenderer) stats(stats string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(stats), "\n") {
+		if len(line) > 1 && strings.HasPrefix(line, "*") && strings.HasSuffix(line, "*") {
+			lines = append(lines, "<b>"+html.EscapeString(strings.Trim(line, "*"))+"</b>")
+		} else {
+			lines = append(lines, html.EscapeString(line))
+		}
+	}
+	return "<p>" + strings.Join(lines, "<br>\n") + "</p>\n"
+}
+
+func (renderer *HTMLRenderer) religious(holidays *ReligiousHolidays) string {
+	formattedStr := "<ul>\n"
+	for _, items := range holidays.Holidays {
+		for _, line := range items.Descriptions {
+			formattedStr += "<li>" + html.EscapeString(line)
+			if items.GroupAbbr != "" {
+				confession := items.confession()
+				title, ok := confessionTitles[confession]
+				if !ok {
+					title = string(confession)
+				}
+				formattedStr += ` <abbr title="` + html.EscapeString(title) + `">` + html.EscapeString(items.GroupAbbr) + "</abbr>"
+			}
+			formattedStr += "</li>\n"
+		}
+	}
+	return formattedStr + "</ul>\n"
+}
+
+func (renderer *HTMLRenderer) Render(report *Report) string {
+	formattedStr := ""
+	if report.Stats != "" {
+		formattedStr += renderer.stats(report.Stats)
+	}
+
+	if len(report.HolidaysInt) > 0 || len(report.HolidaysLoc) > 0 || len(report.HolidaysProf) > 0 || !report.HolidaysRlg.Empty() {
+		formattedStr += "<h2>" + holidaysHeader + "</h2>\n"
+		if len(report.HolidaysInt) > 0 {
+			formattedStr += "<h3>" + intHolidaysSubheader + "</h3>\n" + renderer.list(report.HolidaysInt)
+		}
+		if len(report.HolidaysLoc) > 0 {
+			formattedStr += "<h3>" + locHolidaysSubheader + "</h3>\n" + renderer.list(report.HolidaysLoc)
+		}
+		if len(report.HolidaysProf) > 0 {
+			formattedStr += "<h3>" + profHolidaysSubheader + "</h3>\n" + renderer.list(report.HolidaysProf)
+		}
+		if !report.HolidaysRlg.Empty() {
+			formattedStr += "<h3>" + rlgHolidaysSubheader + "</h3>\n" + renderer.religious(&report.HolidaysRlg)
+		}
+	}
+
+	if len(report.NameDays) > 0 {
+		formattedStr += "<h3>" + nameDaysSubheader + "</h3>\n"
+		formattedStr += "<p>" + html.EscapeString(strings.Join(report.NameDays, ", ")) + "</p>\n"
+	}
+
+	if folk := report.folkDay(); !folk.Empty() {
+		formattedStr += "<h2>" + omensHeader + "</h2>\n"
+		if len(folk.Names) > 0 {
+			formattedStr += "<p><i>" + html.EscapeString(strings.Join(folk.Names, ", ")) + "</i></p>\n"
+		}
+		if len(folk.Omens) > 0 {
+			formattedStr += renderer.list(folk.Omens)
+		}
+		if len(folk.Sayings) > 0 {
+			formattedStr += "<h3>" + sayingsSubheader + "</h3>\n" + renderer.list(folk.Sayings)
+		}
+		if len(folk.Customs) > 0 {
+			formattedStr += "<h3>" + customsSubheader + "</h3>\n" + renderer.list(folk.Customs)
+		}
+	}
+	return formattedStr
+}
@@ -0,0 +1,294 @@
+package wiki
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownV2Renderer(t *testing.T) {
+	fullReport := `== Праздники и памятные дни ==
+
+=== Международные ===
+ ООН — Всемирный день борьбы со СПИДом (World AIDS Day).
+
+=== Профессиональные ===
+ День работника_связи *2019* [архив].
+
+=== Религиозные ===
+ В православной церквиВведение во храм Пресвятой Богородицы
+
+=== Именины ===
+Платон, Роман
+
+== Приметы ==
+Платон и Роман.
+Зима в силу входит!
+`
+	report, err := Parse(fullReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2019, time.December, 1, 1, 1, 1, 1, time.UTC)
+	report.SetCalendarInfo(&day)
+
+	expected := `*Воскресенье, 1 декабря 2019 года*
+335\-й день года\. До конца года 30 дней
+
+*Праздники и памятные дни*
+
+_Международные_
+\- ООН — Всемирный день борьбы со СПИДом \(World AIDS Day\)
+
+_Профессиональные_
+\- День работника\_связи \*2019\* \[архив\]
+
+_Религиозные_
+\- Введение во храм Пресвятой Богородицы \(правосл\.\)
+
+_Именины_
+\- Платон, Роман
+
+*Приметы*
+
+_Платон и Роман_
+Зима в силу входит\!
+`
+	validateStrings(t, expected, NewMarkdownV2Renderer().Render(&report))
+}
+
+func TestLegacyMarkdownRenderer(t *testing.T) {
+	report := Report{HolidaysInt: []string{"День_(1)"}}
+	validateStrings(t, report.String(), NewLegacyMarkdownRenderer().Render(&report))
+	validateStrings(t, "*Праздники и памятные дни*\n\n_Международные_\n- День_(1)\n", report.String())
+}
+
+func TestEscapeMarkdownV2(t *testing.T) {
+	validateStrings(t, `a\\b\_c\*d\[e\]f\(g\)h\~i\`+"`"+`j\>k\#l\+m\-n\=o\|p\{q\}r\.s\!`,
+		EscapeMarkdownV2("a\\b_c*d[e]f(g)h~i`j>k#l+m-n=o|p{q}r.s!"))
+}
+
+const htmlTestReport = `== Праздники и памятные дни ==
+
+=== Национальные ===
+ США — День <благодарения> & «индейки».
+
+=== Религиозные ===
+ В православной церквиВведение во храм Пресвятой Богородицы
+
+=== Именины ===
+Платон, Роман
+
+== Приметы ==
+Платон и Роман.
+Зима в силу входит
+`
+
+func TestHTMLRenderer(t *testing.T) {
+	report, err := Parse(htmlTestReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2019, time.December, 1, 1, 1, 1, 1, time.UTC)
+	report.SetCalendarInfo(&day)
+
+	expected := `<p><b>Воскресенье, 1 декабря 2019 года</b><br>
+335-й день года. До конца года 30 дней</p>
+<h2>Праздники и памятные дни</h2>
+<h3>Национальные</h3>
+<ul>
+<li>США — День &lt;благодарения&gt; &amp; «индейки»</li>
+</ul>
+<h3>Религиозные</h3>
+<ul>
+<li>Введение во храм Пресвятой Богородицы <abbr title="Православие">правосл.</abbr></li>
+</ul>
+<h3>Именины</h3>
+<p>Платон, Роман</p>
+<h2>Приметы</h2>
+<p><i>Платон и Роман</i></p>
+<ul>
+<li>Зима в силу входит</li>
+</ul>
+`
+	validateStrings(t, expected, NewHTMLRenderer().Render(&report))
+}
+
+func TestTelegramHTMLRenderer(t *testing.T) {
+	report, err := Parse(htmlTestReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<b>Праздники и памятные дни</b>
+
+<i>Национальные</i>
+- США — День &lt;благодарения&gt; &amp; «индейки»
+
+<i>Религиозные</i>
+- Введение во храм Пресвятой Богородицы (правосл.)
+
+<i>Именины</i>
+- Платон, Роман
+
+<b>Приметы</b>
+
+<i>Платон и Роман</i>
+Зима в силу входит
+`
+	validateStrings(t, expected, NewTelegramHTMLRenderer().Render(&report))
+}
+
+func renderOptionsReport() Report {
+	return Report{
+		Stats:        "*Воскресенье, 1 декабря 2019 года*\n335-й день года",
+		HolidaysInt:  []string{"Всемирный день борьбы со СПИДом", "День компьютерной графики"},
+		HolidaysProf: []string{"День юриста", "День бухгалтера", "День хоккея"},
+		NameDays:     []string{"Платон", "Роман"},
+		Omens:        []string{"Платон и Роман.", "Зима в силу входит!", "Коли снег — будет урожай"},
+	}
+}
+
+func TestMessageRenderer_RenderWith(t *testing.T) {
+	report := renderOptionsReport()
+	renderer := NewLegacyMarkdownRenderer()
+	validateStrings(t, renderer.Render(&report), renderer.RenderWith(&report, RenderOptions{}))
+
+	options := RenderOptions{Sections: []RenderSection{SectionProfessional, SectionOmens}, MaxItems: 1}
+	expected := `*Праздники и памятные дни*
+
+_Профессиональные_
+- День юриста
+…и ещё 2
+
+*Приметы*
+
+_Платон и Роман._
+Зима в силу входит!
+…и ещё 1
+`
+	validateStrings(t, expected, renderer.RenderWith(&report, options))
+}
+
+func TestMessageRenderer_RenderWithBudget(t *testing.T) {
+	report := renderOptionsReport()
+	renderer := NewLegacyMarkdownRenderer()
+	full := renderer.Render(&report)
+	// the omens are trimmed before the name days and the holidays
+	options := RenderOptions{MaxLength: renderer.visible(full) - 1}
+	expected := strings.Replace(full, "Коли снег — будет урожай\n", "…и ещё 1\n", 1)
+	validateStrings(t, expected, renderer.RenderWith(&report, options))
+
+	options.MaxLength = 231
+	expected = `*Воскресенье, 1 декабря 2019 года*
+335-й день года
+*Праздники и памятные дни*
+
+_Международные_
+- Всемирный день борьбы со СПИДом
+- День компьютерной графики
+
+_Профессиональные_
+…и ещё 3
+
+_Именины_
+…и ещё 1
+
+*Приметы*
+
+_Платон и Роман._
+…и ещё 2
+`
+	validateStrings(t, expected, renderer.RenderWith(&report, options))
+	if length := renderer.visible(expected); length > options.MaxLength {
+		t.Error("The message is over the budget:", length)
+	}
+
+	// the escapes of MarkdownV2 are not seen by the user and do not take the budget
+	escaped := NewMarkdownV2Renderer()
+	options.MaxLength = escaped.visible(escaped.Render(&report))
+	validateStrings(t, escaped.Render(&report), escaped.RenderWith(&report, options))
+}
+
+func TestMessageRenderer_RenderMessages(t *testing.T) {
+	report := renderOptionsReport()
+	renderer := NewLegacyMarkdownRenderer()
+	messages := renderer.RenderMessages(&report, RenderOptions{})
+	if len(messages) != 1 || messages[0] != renderer.Render(&report) {
+		t.Error("Unexpected messages:", messages)
+	}
+
+	messages = renderer.RenderMessages(&report, RenderOptions{MaxLength: 160})
+	expected := []string{
+		"*Воскресенье, 1 декабря 2019 года*\n335-й день года\n*Праздники и памятные дни*\n\n_Международные_\n- Всемирный день борьбы со СПИДом\n- День компьютерной графики\n",
+		"*Праздники и памятные дни*\n\n_Профессиональные_\n- День юриста\n- День бухгалтера\n- День хоккея\n\n_Именины_\n- Платон, Роман\n",
+		"*Приметы*\n\n_Платон и Роман._\nЗима в силу входит!\nКоли снег — будет урожай\n",
+	}
+	if len(messages) != len(expected) {
+		t.Fatal("Unexpected messages:", messages)
+	}
+	for i := range expected {
+		validateStrings(t, expected[i], messages[i])
+	}
+}
+
+func TestMessageRenderer_VisibleLength(t *testing.T) {
+	for _, test := range []struct {
+		renderer *MessageRenderer
+		text     string
+		length   int
+	}{
+		{NewLegacyMarkdownRenderer(), "*Праздники*\n\n_Международные_\n- День_(1)\n", 36},
+		{NewLegacyMarkdownRenderer(), "*Именины: Ро*ман_*\n", 17},
+		{NewMarkdownV2Renderer(), "*Праздники*\n\n_Международные_\n\\- День\\_\\(1\\)\n", 36},
+		{NewTelegramHTMLRenderer(), "<b>Праздники</b>\n\n<i>Международные</i>\n- День_(1)\n", 36},
+		{NewTelegramHTMLRenderer(), "- День &lt;благодарения&gt; &amp; «индейки»\n", 34},
+	} {
+		if length := test.renderer.visible(test.text); length != test.length {
+			t.Errorf("%q: expected %d, actual %d", test.text, test.length, length)
+		}
+	}
+}
+
+func TestTelegramHTMLRenderer_Budget(t *testing.T) {
+	report := renderOptionsReport()
+	report.HolidaysLoc = []string{"США — День <благодарения> & «индейки»"}
+	renderer := NewTelegramHTMLRenderer()
+	full := renderer.Render(&report)
+	// the tags and the entities are not seen by the user and do not take the budget
+	options := RenderOptions{MaxLength: renderer.visible(full)}
+	validateStrings(t, full, renderer.RenderWith(&report, options))
+
+	options.MaxLength--
+	expected := strings.Replace(full, "Коли снег — будет урожай\n", "…и ещё 1\n", 1)
+	validateStrings(t, expected, renderer.RenderWith(&report, options))
+
+	messages := renderer.RenderMessages(&report, RenderOptions{MaxLength: 160})
+	expected = `<b>Воскресенье, 1 декабря 2019 года</b>
+335-й день года
+<b>Праздники и памятные дни</b>
+
+<i>Международные</i>
+- Всемирный день борьбы со СПИДом
+- День компьютерной графики
+`
+	if len(messages) != 3 {
+		t.Fatal("Unexpected messages:", messages)
+	}
+	validateStrings(t, expected, messages[0])
+	expected = `<b>Праздники и памятные дни</b>
+
+<i>Национальные</i>
+- США — День &lt;благодарения&gt; &amp; «индейки»
+
+<i>Профессиональные</i>
+- День юриста
+- День бухгалтера
+- День хоккея
+`
+	validateStrings(t, expected, messages[1])
+	for _, message := range messages {
+		if length := renderer.visible(message); length > 160 {
+			t.Error("The message is over the budget:", length)
+		}
+	}
+}
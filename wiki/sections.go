@@ -0,0 +1,275 @@
+package wiki
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// kinds of the subheaders inside the holidays section
+const (
+	intSubsection      = "international"
+	locSubsection      = "national"
+	regSubsection      = "regional"
+	profSubsection     = "professional"
+	rlgSubsection      = "religious"
+	nameDaysSubsection = "namedays"
+)
+
+// Section is passed to the SectionHandler with the context of the current line
+type Section struct {
+	Header    string
+	Subheader string
+	Line      int
+	Report    *Report
+
+	parser *Parser
+}
+
+// Warn adds a parse warning at the current line
+func (section *Section) Warn(reason WarningReason, text string) {
+	section.parser.warnings = append(section.parser.warnings, Warning{
+		Line:      section.Line,
+		Header:    section.Header,
+		Subheader: section.Subheader,
+		Reason:    reason,
+		Text:      text,
+	})
+}
+
+// SectionHandler processes one "== ... ==" section of the article
+type SectionHandler interface {
+	// Begin is called on the header of the section
+	Begin(section *Section)
+	// Subheader is called on the "=== ... ===" (level 3) and "==== ... ====" (level 4) headers
+	Subheader(section *Section, level int, subheader string)
+	// Line is called on every non-empty line of the section
+	Line(section *Section, line string)
+}
+
+// RawSectionHandler keeps the lines of the section as they are in Report.Extra under the section header
+type RawSectionHandler struct{}
+
+func (handler *RawSectionHandler) Begin(section *Section) {
+	if section.Report.Extra == nil {
+		section.Report.Extra = map[string][]string{}
+	}
+}
+
+func (handler *RawSectionHandler) Subheader(section *Section, level int, subheader string) {
+}
+
+func (handler *RawSectionHandler) Line(section *Section, line string) {
+	if line = strings.TrimSpace(line); line != "" {
+		section.Report.Extra[section.Header] = append(section.Report.Extra[section.Header], line)
+	}
+}
+
+// builtinHandler routes the section to the parse functions of the Parser
+type builtinHandler struct {
+	begin func(parser *Parser, header string)
+}
+
+func (handler *builtinHandler) Begin(section *Section) {
+	handler.begin(section.parser, section.Header)
+}
+
+func (handler *builtinHandler) Subheader(section *Section, level int, subheader string) {
+	parser := section.parser
+	if level == 3 {
+		parser.setSubheader(subheader)
+	} else if parser.history != nil {
+		parser.setHistoryPeriod(subheader)
+	} else if parser.parser != nil {
+		parser.parser(subheader)
+	}
+}
+
+func (handler *builtinHandler) Line(section *Section, line string) {
+	if section.parser.parser != nil {
+		section.parser.parser(strings.TrimSpace(line))
+	}
+}
+
+var builtinHandlers = map[string]func() SectionHandler{
+	"holidays": func() SectionHandler {
+		return &builtinHandler{func(parser *Parser, header string) {
+			parser.setHeader(header, parser.parseHolidays)
+			parser.holidaysLine = parser.lineNum
+		}}
+	},
+	"omens": func() SectionHandler {
+		return &builtinHandler{func(parser *Parser, header string) {
+			parser.setHeader(header, parser.parseOmens)
+			parser.omensLine = parser.lineNum
+		}}
+	},
+	"events": func() SectionHandler {
+		return &builtinHandler{func(parser *Parser, header string) {
+			parser.setHistoryHeader(header, &parser.report.Events)
+		}}
+	},
+	"births": func() SectionHandler {
+		return &builtinHandler{func(parser *Parser, header string) {
+			parser.setHistoryHeader(header, &parser.report.Births)
+		}}
+	},
+	"deaths": func() SectionHandler {
+		return &builtinHandler{func(parser *Parser, header string) {
+			parser.setHistoryHeader(header, &parser.report.Deaths)
+		}}
+	},
+	// sections which are skipped on purpose
+	"ignore": func() SectionHandler {
+		return &builtinHandler{func(parser *Parser, header string) {
+			parser.reset()
+		}}
+	},
+}
+
+type sectionEntry struct {
+	pattern *regexp.Regexp
+	factory func() SectionHandler
+}
+
+type subsectionEntry struct {
+	pattern *regexp.Regexp
+	kind    string
+}
+
+// SectionRegistry maps the headers of the article to the handlers
+// and the subheaders of the holidays section to their kinds
+type SectionRegistry struct {
+	sync.RWMutex
+	custom      []sectionEntry
+	headers     []sectionEntry
+	subsections []subsectionEntry
+}
+
+type sectionAliases struct {
+	Headers []struct {
+		Handler  string   `json:"handler"`
+		Patterns []string `json:"patterns"`
+	} `json:"headers"`
+	Subheaders []struct {
+		Kind     string   `json:"kind"`
+		Patterns []string `json:"patterns"`
+	} `json:"subheaders"`
+}
+
+// LoadAliases adds the header and subheader patterns of the built-in handlers
+func (registry *SectionRegistry) LoadAliases(reader io.Reader) error {
+	aliases := sectionAliases{}
+	if err := json.NewDecoder(reader).Decode(&aliases); err != nil {
+		return err
+	}
+	var headers []sectionEntry
+	for _, header := range aliases.Headers {
+		factory, ok := builtinHandlers[header.Handler]
+		if !ok {
+			return errors.New("unknown section handler: " + header.Handler)
+		}
+		patterns, err := compilePatterns(header.Patterns)
+		if err != nil {
+			return err
+		}
+		for _, pattern := range patterns {
+			headers = append(headers, sectionEntry{pattern, factory})
+		}
+	}
+	var subsections []subsectionEntry
+	for _, subheader := range aliases.Subheaders {
+		patterns, err := compilePatterns(subheader.Patterns)
+		if err != nil {
+			return err
+		}
+		for _, pattern := range patterns {
+			subsections = append(subsections, subsectionEntry{pattern, subheader.Kind})
+		}
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.headers = append(registry.headers, headers...)
+	registry.subsections = append(registry.subsections, subsections...)
+	return nil
+}
+
+// Register adds a handler for the headers matching the pattern,
+// registered handlers are checked before the built-in ones
+func (registry *SectionRegistry) Register(pattern string, factory func() SectionHandler) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.custom = append(registry.custom, sectionEntry{re, factory})
+	return nil
+}
+
+// Handler returns a new handler for the header or nil when the section is unknown
+func (registry *SectionRegistry) Handler(header string) SectionHandler {
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, entries := range [][]sectionEntry{registry.custom, registry.headers} {
+		for _, entry := range entries {
+			if entry.pattern.MatchString(header) {
+				return entry.factory()
+			}
+		}
+	}
+	return nil
+}
+
+func (registry *SectionRegistry) subsection(subheader string) string {
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, entry := range registry.subsections {
+		if entry.pattern.MatchString(subheader) {
+			return entry.kind
+		}
+	}
+	return ""
+}
+
+func NewSectionRegistry() *SectionRegistry {
+	registry := &SectionRegistry{}
+	if err := registry.LoadAliases(strings.NewReader(defaultSectionAliases)); err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+var sections = NewSectionRegistry()
+
+// RegisterSection adds a handler for the headers matching the pattern to the registry used by Parse
+func RegisterSection(pattern string, factory func() SectionHandler) error {
+	return sections.Register(pattern, factory)
+}
+
+// LoadSectionAliases extends the aliases of the built-in sections used by Parse
+func LoadSectionAliases(reader io.Reader) error {
+	return sections.LoadAliases(reader)
+}
+
+const defaultSectionAliases = `{
+ "headers": [
+  {"handler": "holidays", "patterns": ["^Праздники и памятные дни$", "^Праздники$"]},
+  {"handler": "omens", "patterns": ["^Приметы$", "^Народный календарь(,? (и )?приметы)?( и фольклор Руси)?$"]},
+  {"handler": "events", "patterns": ["^События$"]},
+  {"handler": "births", "patterns": ["^Родились$"]},
+  {"handler": "deaths", "patterns": ["^Скончались$"]},
+  {"handler": "ignore", "patterns": ["^См\\. также$", "^Примечания$", "^Ссылки$", "^Литература$"]}
+ ],
+ "subheaders": [
+  {"kind": "international", "patterns": ["^Международные$", "^Мир$"]},
+  {"kind": "national", "patterns": ["^Национальные$"]},
+  {"kind": "regional", "patterns": ["^Региональные$"]},
+  {"kind": "professional", "patterns": ["^Профессиональные$"]},
+  {"kind": "religious", "patterns": ["^Религиозные$"]},
+  {"kind": "namedays", "patterns": ["^Именины$"]}
+ ]
+}`
@@ -0,0 +1,90 @@
+package wiki
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type countingHandler struct {
+	subheaders []string
+}
+
+func (handler *countingHandler) Begin(section *Section) {
+}
+
+func (handler *countingHandler) Subheader(section *Section, level int, subheader string) {
+	handler.subheaders = append(handler.subheaders, strings.TrimSpace(subheader))
+}
+
+func (handler *countingHandler) Line(section *Section, line string) {
+	if strings.Contains(line, "?") {
+		section.Warn(UnparsedLine, line)
+	}
+}
+
+func TestSectionRegistry_Custom(t *testing.T) {
+	saved := sections
+	sections = NewSectionRegistry()
+	defer func() { sections = saved }()
+
+	handler := &countingHandler{}
+	if err := RegisterSection("^Примечания$", func() SectionHandler { return handler }); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterSection("^Народные поверья$", func() SectionHandler { return &RawSectionHandler{} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadSectionAliases(strings.NewReader(`{"headers": [{"handler": "omens", "patterns": ["^Народные приметы$"]}],
+ "subheaders": [{"kind": "professional", "patterns": ["^Профессиональные праздники$"]}]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	fullReport := `== Праздники и памятные дни ==
+
+=== Профессиональные праздники ===
+ Россия — День юриста.
+
+== Народные поверья ==
+Домовой не любит ссор
+ Кикимора прядёт по ночам
+
+== Народные приметы ==
+Мороз — к урожаю.
+
+== Примечания ==
+=== Источники ===
+Что это?
+`
+	result, err := ParseWithWarnings(fullReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := result.Report
+	if !reflect.DeepEqual(report.HolidaysProf, []string{"Россия — День юриста"}) {
+		t.Error("Unexpected professional holidays:", report.HolidaysProf)
+	}
+	if !reflect.DeepEqual(report.Extra, map[string][]string{"Народные поверья": {"Домовой не любит ссор", "Кикимора прядёт по ночам"}}) {
+		t.Error("Unexpected raw sections:", report.Extra)
+	}
+	if !reflect.DeepEqual(report.Omens, []string{"Мороз — к урожаю."}) {
+		t.Error("Unexpected omens:", report.Omens)
+	}
+	if !reflect.DeepEqual(handler.subheaders, []string{"Источники"}) {
+		t.Error("Unexpected subheaders:", handler.subheaders)
+	}
+	expected := []Warning{{Line: 15, Header: "Примечания", Subheader: "Источники", Reason: UnparsedLine, Text: "Что это?"}}
+	if !reflect.DeepEqual(expected, result.Warnings) {
+		t.Error("Expected:\n", expected, "\nActual:\n", result.Warnings)
+	}
+}
+
+func TestSectionRegistry_InvalidAliases(t *testing.T) {
+	registry := NewSectionRegistry()
+	if err := registry.LoadAliases(strings.NewReader(`{"headers": [{"handler": "unknown", "patterns": ["^X$"]}]}`)); err == nil {
+		t.Error("Expected error for unknown handler")
+	}
+	if err := registry.Register("(", func() SectionHandler { return &RawSectionHandler{} }); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,227 @@
+package wiki
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SnapshotSchema is the version of the snapshot layout written by Snapshot.Write,
+// the bare month -> day maps of holidays.v1.*.json are the schema 1
+const SnapshotSchema = 2
+
+// ParserVersion is the version of the parser stored in the snapshots
+const ParserVersion = "1.18"
+
+const DefaultLanguage = "ru"
+
+var ErrSnapshotHash = errors.New("snapshot hash does not match the days")
+
+// SnapshotDay is the report of one day together with the revision of the article it was parsed from
+type SnapshotDay struct {
+	Month    time.Month `json:"month"`
+	Day      int        `json:"day"`
+	Revision uint64     `json:"revision,omitempty"`
+	Report   Report     `json:"report"`
+}
+
+// Snapshot is the dataset of the reports of all the days with the metadata of its generation
+type Snapshot struct {
+	Schema    int       `json:"schema"`
+	Generated time.Time `json:"generated"`
+	// API endpoint of the wiki the pages were loaded from
+	Source   string `json:"source"`
+	Language string `json:"language"`
+	Parser   string `json:"parser"`
+	// sha256 of the days as they are written, without the whitespace between the tokens
+	Hash string        `json:"hash"`
+	Days []SnapshotDay `json:"days"`
+}
+
+func NewSnapshot(source string) *Snapshot {
+	return &Snapshot{
+		Schema:    SnapshotSchema,
+		Generated: time.Now().UTC(),
+		Source:    source,
+		Language:  DefaultLanguage,
+		Parser:    ParserVersion,
+	}
+}
+
+// Add puts the report of the day to the snapshot, the days are kept in the calendar order
+func (snapshot *Snapshot) Add(month time.Month, day int, revision uint64, report Report) {
+	i := sort.Search(len(snapshot.Days), func(i int) bool {
+		d := &snapshot.Days[i]
+		return d.Month > month || d.Month == month && d.Day >= day
+	})
+	entry := SnapshotDay{Month: month, Day: day, Revision: revision, Report: report}
+	if i < len(snapshot.Days) && snapshot.Days[i].Month == month && snapshot.Days[i].Day == day {
+		snapshot.Days[i] = entry
+		return
+	}
+	snapshot.Days = append(snapshot.Days, SnapshotDay{})
+	copy(snapshot.Days[i+1:], snapshot.Days[i:])
+	snapshot.Days[i] = entry
+}
+
+// Day returns the entry of the day or nil when the day is missing
+func (snapshot *Snapshot) Day(month time.Month, day int) *SnapshotDay {
+	for i := range snapshot.Days {
+		if snapshot.Days[i].Month == month && snapshot.Days[i].Day == day {
+			return &snapshot.Days[i]
+		}
+	}
+	return nil
+}
+
+// hashDays hashes the json of the days compacted, so the pretty snapshots have the same hash
+func hashDays(days []byte) (string, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, days); err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(compact.Bytes())
+	return "sha256:" + hex.EncodeToString(hash[:]), nil
+}
+
+func (snapshot *Snapshot) contentHash() (string, error) {
+	days, err := json.Marshal(snapshot.Days)
+	if err != nil {
+		return "", err
+	}
+	return hashDays(days)
+}
+
+// Write stores the snapshot with the hash of its days
+func (snapshot *Snapshot) Write(writer io.Writer, pretty bool) error {
+	hash, err := snapshot.contentHash()
+	if err != nil {
+		return err
+	}
+	snapshot.Hash = hash
+	var contents []byte
+	if pretty {
+		contents, err = json.MarshalIndent(snapshot, "", " ")
+	} else {
+		contents, err = json.Marshal(snapshot)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(contents)
+	return err
+}
+
+// the schema 1 layout: {"1": {"1": {"month": "January", "day": "1", "report": {...}}}}
+type snapshotV1Day struct {
+	Revision uint64 `json:"revision"`
+	Report   Report `json:"report"`
+}
+
+// migrateReportV1 fills the fields the schema 1 reports do not have from their flat lists.
+// The flat lists have lost the subsections and the markers of the article, so the traditions,
+// the genders and the calendar styles of the name days stay empty and the regional holidays
+// get their country only when the region is known
+func migrateReportV1(report *Report, rules *ConfessionRules) {
+	for _, holiday := range report.HolidaysRlg.Holidays {
+		if holiday.Confession == "" {
+			holiday.Confession = rules.ByAbbr(holiday.GroupAbbr)
+		}
+	}
+	parser := Parser{report: report}
+	if len(report.LocalHolidays) == 0 {
+		for _, line := range report.HolidaysLoc {
+			parser.addLocalHoliday(line)
+		}
+	}
+	if len(report.NameDayEntries) == 0 {
+		parser.addNameEntries(report.NameDays)
+	}
+	if len(report.Omens) > 0 && report.FolkDay.Empty() {
+		report.FolkDay = NewFolkDay(report.Omens)
+	}
+}
+
+func migrateSnapshotV1(contents []byte) (*Snapshot, error) {
+	months := map[string]map[string]snapshotV1Day{}
+	if err := json.Unmarshal(contents, &months); err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{Schema: SnapshotSchema, Language: DefaultLanguage}
+	rules := getConfessionRules()
+	for monthKey, days := range months {
+		month, err := strconv.Atoi(monthKey)
+		if err != nil || month < 1 || month > 12 {
+			return nil, errors.New("unknown month: " + monthKey)
+		}
+		for dayKey, day := range days {
+			d, err := strconv.Atoi(dayKey)
+			if err != nil {
+				return nil, errors.New("unknown day: " + dayKey)
+			}
+			migrateReportV1(&day.Report, rules)
+			snapshot.Add(time.Month(month), d, day.Revision, day.Report)
+		}
+	}
+	snapshot.Hash, _ = snapshot.contentHash()
+	return snapshot, nil
+}
+
+// LoadSnapshot reads the snapshot of any schema, the older ones are migrated to the current one
+// with the metadata they do not have left empty
+func LoadSnapshot(reader io.Reader) (*Snapshot, error) {
+	contents, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Schema int `json:"schema"`
+	}
+	// the maps of the schema 1 have no schema field
+	if err := json.Unmarshal(contents, &header); err != nil {
+		return nil, err
+	}
+	switch header.Schema {
+	case 0:
+		return migrateSnapshotV1(contents)
+	case SnapshotSchema:
+	default:
+		return nil, errors.New("unsupported snapshot schema: " + strconv.Itoa(header.Schema))
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(contents, snapshot); err != nil {
+		return nil, err
+	}
+	// the days are checked as they are written, the reports may have gained or lost fields since then
+	var raw struct {
+		Days json.RawMessage `json:"days"`
+	}
+	if err := json.Unmarshal(contents, &raw); err != nil {
+		return nil, err
+	}
+	hash, err := hashDays(raw.Days)
+	if err != nil {
+		return nil, err
+	}
+	if hash != snapshot.Hash {
+		return nil, ErrSnapshotHash
+	}
+	return snapshot, nil
+}
+
+func LoadSnapshotFile(path string) (*Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadSnapshot(file)
+}
@@ -0,0 +1,112 @@
+package wiki
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const snapshotV1 = `{
+ "12": {
+  "4": {"month": "December", "day": "4", "report": {"HolidaysInt": ["День банков"]}},
+  "1": {"month": "December", "day": "1", "report": {"HolidaysInt": ["Всемирный день борьбы со СПИДом"]}, "revision": 42}
+ },
+ "1": {
+  "1": {"month": "January", "day": "1", "report": {
+   "HolidaysInt": ["Новый год"],
+   "HolidaysLoc": ["Куба — День освобождения", "Татарстан — День родного языка"],
+   "HolidaysRlg": {"Holidays": [{"Descriptions": ["Торжество Пресвятой Богородицы"], "GroupAbbr": "катол."}, {"Descriptions": ["Весак"], "GroupAbbr": ""}]},
+   "NameDays": ["Илья", "Сатурнин/Саторнин"],
+   "Omens": ["Новый год", "Если в этот день тепло, будет урожай"]
+  }}
+ }
+}`
+
+func TestLoadSnapshot_V1(t *testing.T) {
+	snapshot, err := LoadSnapshot(strings.NewReader(snapshotV1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Schema != SnapshotSchema || len(snapshot.Days) != 3 {
+		t.Fatal("Unexpected snapshot:", snapshot)
+	}
+	expected := []struct {
+		month time.Month
+		day   int
+	}{{time.January, 1}, {time.December, 1}, {time.December, 4}}
+	for i, date := range expected {
+		if snapshot.Days[i].Month != date.month || snapshot.Days[i].Day != date.day {
+			t.Error("Unexpected day order:", snapshot.Days[i].Month, snapshot.Days[i].Day)
+		}
+	}
+	day := snapshot.Day(time.December, 1)
+	if day == nil || day.Revision != 42 || day.Report.HolidaysInt[0] != "Всемирный день борьбы со СПИДом" {
+		t.Error("Unexpected day:", day)
+	}
+	if snapshot.Day(time.December, 2) != nil {
+		t.Error("Expected missing day")
+	}
+
+	report := &snapshot.Day(time.January, 1).Report
+	if confessions := []Confession{report.HolidaysRlg.Holidays[0].Confession, report.HolidaysRlg.Holidays[1].Confession}; !reflect.DeepEqual(confessions, []Confession{ConfessionCatholic, ""}) {
+		t.Error("Unexpected confessions:", confessions)
+	}
+	expectedLocal := []LocalHoliday{
+		{Countries: []string{"CU"}, Title: "День освобождения"},
+		{Countries: []string{"RU"}, Region: "Татарстан", Title: "День родного языка"},
+	}
+	if !reflect.DeepEqual(expectedLocal, report.LocalHolidays) {
+		t.Error("Expected:\n", expectedLocal, "\nActual:\n", report.LocalHolidays)
+	}
+	expectedNames := []NameDay{{Name: "Илья"}, {Name: "Сатурнин/Саторнин"}}
+	if !reflect.DeepEqual(expectedNames, report.NameDayEntries) {
+		t.Error("Expected:\n", expectedNames, "\nActual:\n", report.NameDayEntries)
+	}
+	if !reflect.DeepEqual(NewFolkDay(report.Omens), report.FolkDay) || report.FolkDay.Empty() {
+		t.Error("Unexpected folk day:", report.FolkDay)
+	}
+}
+
+func TestLoadSnapshot_V2Fixture(t *testing.T) {
+	snapshot, err := LoadSnapshotFile("testdata/snapshot.v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Parser != "1.16" || len(snapshot.Days) != 1 {
+		t.Fatal("Unexpected snapshot:", snapshot)
+	}
+	day := snapshot.Day(time.January, 1)
+	if day == nil || day.Revision != 7 || day.Report.HolidaysRlg.Holidays[0].Confession != ConfessionCatholic {
+		t.Error("Unexpected day:", day)
+	}
+}
+
+func TestSnapshot_Write(t *testing.T) {
+	snapshot := NewSnapshot(DefaultApiUrl)
+	snapshot.Add(time.December, 4, 7, Report{HolidaysInt: []string{"День банков"}})
+	snapshot.Add(time.December, 1, 42, Report{HolidaysInt: []string{"Всемирный день борьбы со СПИДом"}})
+	var buffer bytes.Buffer
+	if err := snapshot.Write(&buffer, false); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSnapshot(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Source != DefaultApiUrl || loaded.Parser != ParserVersion || loaded.Language != DefaultLanguage || !loaded.Generated.Equal(snapshot.Generated) {
+		t.Error("Unexpected metadata:", loaded)
+	}
+	if len(loaded.Days) != 2 || loaded.Days[0].Day != 1 || loaded.Days[1].Revision != 7 {
+		t.Error("Unexpected days:", loaded.Days)
+	}
+
+	tampered := strings.Replace(buffer.String(), "День банков", "День банка", 1)
+	if _, err := LoadSnapshot(strings.NewReader(tampered)); err != ErrSnapshotHash {
+		t.Error("Expected ErrSnapshotHash, got", err)
+	}
+	if _, err := LoadSnapshot(strings.NewReader(`{"schema": 3}`)); err == nil {
+		t.Error("Expected unsupported schema error")
+	}
+}
@@ -0,0 +1,194 @@
+package wiki
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const DefaultApiUrl = "https://ru.wikipedia.org/w/api.php"
+
+var ErrPageNotFound = errors.New("page not found")
+
+// Page is a single article extract returned by a PageSource
+type Page struct {
+	Title      string
+	Extract    string
+	RevisionId uint64
+}
+
+// PageSource provides plain-text extracts of the day articles by title, e.g. "1 января"
+type PageSource interface {
+	GetPage(title string) (Page, error)
+}
+
+// HttpSource loads extracts from the MediaWiki API
+type HttpSource struct {
+	ApiUrl string
+	Client *http.Client
+}
+
+func NewHttpSource() *HttpSource {
+	return &HttpSource{ApiUrl: DefaultApiUrl, Client: http.DefaultClient}
+}
+
+// RevisionSource reports the current revision ids of the pages without loading the extracts,
+// missing pages are left out of the result
+type RevisionSource interface {
+	GetRevisions(titles []string) (map[string]uint64, error)
+}
+
+// the API limit of the titles in one query
+const revisionsBatch = 50
+
+func (source *HttpSource) query(params string) (Response, error) {
+	apiUrl := source.ApiUrl
+	if apiUrl == "" {
+		apiUrl = DefaultApiUrl
+	}
+	client := source.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	wikiRequest := apiUrl + "?action=query&format=json&" + params
+
+	log.Print(wikiRequest)
+	var wr Response
+	response, err := client.Get(wikiRequest)
+	if err != nil {
+		return wr, err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			log.Print(err)
+		}
+	}()
+	if response.StatusCode != http.StatusOK {
+		return wr, errors.New("unexpected response status: " + response.Status)
+	}
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return wr, err
+	}
+	err = json.Unmarshal(contents, &wr)
+	return wr, err
+}
+
+func (source *HttpSource) GetPage(title string) (Page, error) {
+	wr, err := source.query("prop=extracts%7Crevisions&rvprop=ids&exlimit=1&explaintext&titles=" + url.QueryEscape(title))
+	if err != nil {
+		return Page{}, err
+	}
+	if l := len(wr.Query.Pages); l != 1 {
+		return Page{}, errors.New("there must be only one page")
+	}
+	var page Page
+	for _, v := range wr.Query.Pages {
+		page = Page{Title: v.Title, Extract: v.Extract}
+		if len(v.Revisions) > 0 {
+			page.RevisionId = v.Revisions[0].RevId
+		}
+	}
+	if page.Extract == "" {
+		return page, ErrPageNotFound
+	}
+	return page, nil
+}
+
+func (source *HttpSource) GetRevisions(titles []string) (map[string]uint64, error) {
+	revisions := map[string]uint64{}
+	for start := 0; start < len(titles); start += revisionsBatch {
+		end := start + revisionsBatch
+		if end > len(titles) {
+			end = len(titles)
+		}
+		wr, err := source.query("prop=revisions&rvprop=ids&titles=" + url.QueryEscape(strings.Join(titles[start:end], "|")))
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range wr.Query.Pages {
+			if len(v.Revisions) > 0 {
+				revisions[v.Title] = v.Revisions[0].RevId
+			}
+		}
+	}
+	return revisions, nil
+}
+
+// DirSource reads extracts from "<Dir>/<title>.txt" files,
+// the revision id is kept in an optional "<title>.rev" file next to it
+type DirSource struct {
+	Dir string
+}
+
+func (source *DirSource) GetPage(title string) (Page, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(source.Dir, title+".txt"))
+	if os.IsNotExist(err) {
+		return Page{}, ErrPageNotFound
+	} else if err != nil {
+		return Page{}, err
+	}
+	page := Page{Title: title, Extract: string(contents)}
+	revision, err := ioutil.ReadFile(filepath.Join(source.Dir, title+".rev"))
+	if err == nil {
+		page.RevisionId, err = strconv.ParseUint(strings.TrimSpace(string(revision)), 10, 64)
+		if err != nil {
+			return Page{}, err
+		}
+	} else if !os.IsNotExist(err) {
+		return Page{}, err
+	}
+	return page, nil
+}
+
+func (source *DirSource) SavePage(page Page) error {
+	if err := os.MkdirAll(source.Dir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(source.Dir, page.Title+".txt"), []byte(page.Extract), 0644); err != nil {
+		return err
+	}
+	revision := strconv.FormatUint(page.RevisionId, 10) + "\n"
+	return ioutil.WriteFile(filepath.Join(source.Dir, page.Title+".rev"), []byte(revision), 0644)
+}
+
+// MemorySource keeps extracts in memory, mostly for tests
+type MemorySource struct {
+	sync.RWMutex
+	pages map[string]string
+}
+
+func NewMemorySource(pages map[string]string) *MemorySource {
+	source := &MemorySource{pages: map[string]string{}}
+	for title, extract := range pages {
+		source.pages[title] = extract
+	}
+	return source
+}
+
+func (source *MemorySource) Put(title string, extract string) {
+	source.Lock()
+	defer source.Unlock()
+	if source.pages == nil {
+		source.pages = map[string]string{}
+	}
+	source.pages[title] = extract
+}
+
+func (source *MemorySource) GetPage(title string) (Page, error) {
+	source.RLock()
+	defer source.RUnlock()
+	extract, ok := source.pages[title]
+	if !ok {
+		return Page{}, ErrPageNotFound
+	}
+	return Page{Title: title, Extract: extract}, nil
+}
@@ -0,0 +1,124 @@
+package wiki
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const sourceTestExtract = `== Праздники и памятные дни ==
+=== Международные ===
+ ООН — Всемирный день борьбы со СПИДом
+`
+
+func TestMemorySource(t *testing.T) {
+	source := NewMemorySource(map[string]string{"1 декабря": sourceTestExtract})
+	page, err := source.GetPage("1 декабря")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateStrings(t, sourceTestExtract, page.Extract)
+	if _, err := source.GetPage("2 декабря"); err != ErrPageNotFound {
+		t.Error("Expected ErrPageNotFound, got", err)
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wiki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "1 декабря.txt"), []byte(sourceTestExtract), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source := DirSource{Dir: dir}
+	page, err := source.GetPage("1 декабря")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateStrings(t, sourceTestExtract, page.Extract)
+	if page.RevisionId != 0 {
+		t.Error("Expected no revision, got", page.RevisionId)
+	}
+
+	if err := source.SavePage(Page{Title: "4 декабря", Extract: "text", RevisionId: 42}); err != nil {
+		t.Fatal(err)
+	}
+	page, err = source.GetPage("4 декабря")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Extract != "text" || page.RevisionId != 42 {
+		t.Error("Unexpected page:", page)
+	}
+	if _, err := source.GetPage("2 декабря"); err != ErrPageNotFound {
+		t.Error("Expected ErrPageNotFound, got", err)
+	}
+}
+
+func TestHttpSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if title := r.URL.Query().Get("titles"); title != "1 декабря" {
+			t.Error("Unexpected title:", title)
+		}
+		w.Write([]byte(`{"batchcomplete":"","query":{"pages":{"1":{"pageid":1,"ns":0,"title":"1 декабря","extract":"text","revisions":[{"revid":42,"parentid":41}]}}}}`))
+	}))
+	defer server.Close()
+
+	source := HttpSource{ApiUrl: server.URL}
+	page, err := source.GetPage("1 декабря")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateStrings(t, "text", page.Extract)
+	if page.RevisionId != 42 {
+		t.Error("Expected revision 42, got", page.RevisionId)
+	}
+}
+
+func TestReportCache(t *testing.T) {
+	source := NewMemorySource(map[string]string{"1 декабря": sourceTestExtract})
+	cache := NewReportCache(source)
+	day := time.Date(2019, time.December, 1, 1, 1, 1, 1, time.UTC)
+	report := cache.getCachedReport(&day)
+	if len(report.HolidaysInt) != 1 {
+		t.Error("Expected one international holiday, got", report.HolidaysInt)
+	}
+	source.Put("1 декабря", "")
+	report = cache.getCachedReport(&day)
+	if len(report.HolidaysInt) != 1 {
+		t.Error("Expected cached report, got", report.HolidaysInt)
+	}
+}
+
+func TestHttpSource_GetRevisions(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if prop := r.URL.Query().Get("prop"); prop != "revisions" {
+			t.Error("Unexpected prop:", prop)
+		}
+		if titles := r.URL.Query().Get("titles"); titles != "1 декабря|2 декабря" {
+			t.Error("Unexpected titles:", titles)
+		}
+		w.Write([]byte(`{"batchcomplete":"","query":{"pages":{"1":{"pageid":1,"ns":0,"title":"1 декабря","revisions":[{"revid":42,"parentid":41}]},"-1":{"ns":0,"title":"2 декабря","missing":""}}}}`))
+	}))
+	defer server.Close()
+
+	source := HttpSource{ApiUrl: server.URL}
+	revisions, err := source.GetRevisions([]string{"1 декабря", "2 декабря"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || revisions["1 декабря"] != 42 {
+		t.Error("Unexpected revisions:", revisions)
+	}
+	if requests != 1 {
+		t.Error("Expected one request, got", requests)
+	}
+}
@@ -1,11 +1,8 @@
 package wiki
 
 import (
-	"encoding/json"
-	"io/ioutil"
 	"log"
-	"net/http"
-	"net/url"
+	"math/rand"
 	"strconv"
 	"strings"
 	"sync"
@@ -47,7 +44,7 @@ const regHolidaysSubheader = "Региональные"
 
 const MoscowLocation = "Europe/Moscow"
 
-var reportCache = ReportCache{}
+var reportCache = NewReportCache(NewHttpSource())
 
 type Report struct {
 	Stats        string
@@ -55,15 +52,36 @@ type Report struct {
 	HolidaysInt  []string
 	HolidaysLoc  []string
 	HolidaysProf []string
-	HolidaysRlg  ReligiousHolidays
-	NameDays     []string
-	Omens        []string
+	// HolidaysLoc split into countries and titles
+	LocalHolidays []LocalHoliday
+	HolidaysRlg   ReligiousHolidays
+	NameDays      []string
+	// NameDays with the traditions, a name may repeat for the different traditions
+	NameDayEntries []NameDay
+	Omens          []string
+	// Omens split into the names of the day, omens, sayings and customs
+	FolkDay FolkDay
+	Events  []*HistoryGroup
+	Births  []*HistoryGroup
+	Deaths  []*HistoryGroup
+	// lines of the sections collected by RawSectionHandler
+	Extra map[string][]string
 	//sections     map[string][]*Section
 }
 
 type ReligiousHolidayDescr struct {
 	Descriptions []string
 	GroupAbbr    string
+	Confession   Confession
+}
+
+// confession returns the confession of the group, the reports written before the confessions were kept
+// have only the abbreviation
+func (descr *ReligiousHolidayDescr) confession() Confession {
+	if descr.Confession != "" {
+		return descr.Confession
+	}
+	return getConfessionRules().ByAbbr(descr.GroupAbbr)
 }
 
 type ReligiousHolidays struct {
@@ -80,6 +98,20 @@ func (holidays *ReligiousHolidays) Empty() bool {
 	return empty
 }
 
+// Filter keeps only the groups of the given confessions
+func (holidays *ReligiousHolidays) Filter(confessions ...Confession) ReligiousHolidays {
+	filtered := ReligiousHolidays{}
+	for _, item := range holidays.Holidays {
+		for _, confession := range confessions {
+			if item.confession() == confession {
+				filtered.Holidays = append(filtered.Holidays, item)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
 func (holidays *ReligiousHolidays) AppendString(formatted *string) {
 	if len(holidays.Holidays) > 0 {
 		for _, items := range holidays.Holidays {
@@ -100,75 +132,22 @@ func (holidays *ReligiousHolidays) AppendString(formatted *string) {
 //}
 
 func (report *Report) String() string {
-	formattedStr := ""
-	if report.Stats != "" {
-		formattedStr += report.Stats + "\n"
-	}
-
-	if len(report.HolidaysInt) > 0 || len(report.HolidaysLoc) > 0 || len(report.HolidaysProf) > 0 || !report.HolidaysRlg.Empty() {
-		formattedStr += "*" + holidaysHeader + "*\n"
-		if len(report.HolidaysInt) > 0 {
-			formattedStr += "\n_" + intHolidaysSubheader + "_\n"
-			for _, line := range report.HolidaysInt {
-				formattedStr += "- " + line + "\n"
-			}
-		}
-		if len(report.HolidaysLoc) > 0 {
-			formattedStr += "\n_" + locHolidaysSubheader + "_\n"
-			for _, line := range report.HolidaysLoc {
-				formattedStr += "- " + line + "\n"
-			}
-		}
-		if len(report.HolidaysProf) > 0 {
-			formattedStr += "\n_" + profHolidaysSubheader + "_\n"
-			for _, line := range report.HolidaysProf {
-				formattedStr += "- " + line + "\n"
-			}
-		}
-		if !report.HolidaysRlg.Empty() {
-			formattedStr += "\n_" + rlgHolidaysSubheader + "_\n"
-			report.HolidaysRlg.AppendString(&formattedStr)
-		}
-	}
-
-	if len(report.NameDays) > 0 {
-		formattedStr += "\n_" + nameDaysSubheader + "_"
-		append := false
-		for _, line := range report.NameDays {
-			if strings.Contains(line, ":") {
-				formattedStr += "\n- " + line
-				append = false
-			} else {
-				if append {
-					formattedStr += ", " + line
-				} else {
-					formattedStr += "\n- " + line
-					append = true
-				}
-			}
-		}
-		formattedStr += "\n"
-	}
+	return NewLegacyMarkdownRenderer().Render(report)
+}
 
-	if l := len(report.Omens); l > 0 {
-		formattedStr += "\n*" + "Приметы" + "*\n\n"
-		for i, line := range report.Omens {
-			if i > 0 && i < 5 {
-				formattedStr += line + "\n"
-			} else if i == 0 {
-				formattedStr += "_" + line + "_\n"
-			} else {
-				break
-			}
-		}
-	}
-	return formattedStr
+// StringWith formats the report as String does with the sections, the length and the layout set by the options
+func (report *Report) StringWith(options RenderOptions) string {
+	return NewLegacyMarkdownRenderer().RenderWith(report, options)
 }
 
 func (report *Report) SetCalendarInfo(day *time.Time) {
 	report.Stats = GenerateCalendarStats(day)
 }
 
+func (report *Report) SetCalendarInfoWith(day *time.Time, options CalendarStatsOptions) {
+	report.Stats = GenerateCalendarStatsWith(day, options)
+}
+
 type Response struct {
 	Batchcomplete string `json:"batchcomplete"`
 	Query         Query  `json:"query"`
@@ -179,56 +158,39 @@ type Query struct {
 }
 
 type Pages struct {
-	Title   string `json:"title"`
-	Extract string `json:"extract"`
-	PageId  uint64 `json:"pageid"`
-	NS      uint64 `json:"ns"`
+	Title     string     `json:"title"`
+	Extract   string     `json:"extract"`
+	PageId    uint64     `json:"pageid"`
+	NS        uint64     `json:"ns"`
+	Revisions []Revision `json:"revisions"`
 }
 
-func getWikiReport(reportDay *time.Time) string {
-	wikiRequest := "https://ru.wikipedia.org/w/api.php?action=query&format=json&&prop=extracts&exlimit=1&explaintext"
-	data := getDateString(reportDay)
-	wikiRequest += "&titles=" + url.QueryEscape(data)
+type Revision struct {
+	RevId    uint64 `json:"revid"`
+	ParentId uint64 `json:"parentid"`
+}
 
-	log.Print(wikiRequest)
-	if response, err := http.Get(wikiRequest); err != nil {
-		log.Print("Wikipedia is not respond")
-	} else {
-		defer func() {
-			if err := response.Body.Close(); err != nil {
-				log.Print(err)
-			}
-		}()
-		contents, err := ioutil.ReadAll(response.Body)
-		if err != nil {
-			log.Print(err)
-			return ""
-		}
-		var wr Response
-		if err := json.Unmarshal(contents, &wr); err != nil {
-			log.Print("Error", err)
-			return ""
-		}
+func GetTodaysReport() string {
+	return reportCache.GetTodaysReport()
+}
 
-		if l := len(wr.Query.Pages); l == 0 || l > 1 {
-			log.Print("There must be only one page - ", l)
-			return ""
-		}
-		var content string
-		for _, v := range wr.Query.Pages {
-			content = v.Extract
-		}
-		return content
-	}
-	return ""
+// GetTodaysReportAs formats the report of today with the given renderer
+func GetTodaysReportAs(renderer Renderer) string {
+	return reportCache.GetTodaysReportAs(renderer)
 }
 
-func GetTodaysReport() string {
-	location, _ := time.LoadLocation(MoscowLocation)
-	log.Print(location)
-	now := time.Now().In(location)
-	report := reportCache.getCachedReport(&now)
-	return report.String()
+func GetTodaysReportWithHistory(events int) string {
+	return reportCache.GetTodaysReportWithHistory(events)
+}
+
+// SetCalendarStatsOptions changes the stats of the reports of today
+func SetCalendarStatsOptions(options CalendarStatsOptions) {
+	reportCache.SetCalendarStatsOptions(options)
+}
+
+// GetTodaysMessages splits the report of today into the messages within options.MaxLength
+func GetTodaysMessages(renderer *MessageRenderer, options RenderOptions) []string {
+	return reportCache.GetTodaysMessages(renderer, options)
 }
 
 func getDateString(day *time.Time) string {
@@ -261,8 +223,34 @@ func GetDayNoun(day int) string {
 	}
 }
 
+// CalendarStatsOptions choose the optional parts of the calendar stats
+type CalendarStatsOptions struct {
+	// JulianDate adds the date of the old style calendar next to the date
+	JulianDate bool
+	// ProductionCalendar adds the kind of the day and the working days left
+	// by the built-in calendar or the one loaded by LoadProductionCalendar
+	ProductionCalendar bool
+}
+
+// julianDateString is the old style date of the day: "18 ноября по старому стилю",
+// the year is told only when it differs
+func julianDateString(reportDay *time.Time) string {
+	julian := ToJulian(*reportDay)
+	if julian.Year != reportDay.Year() {
+		return julian.String() + " по старому стилю"
+	}
+	return strconv.Itoa(julian.Day) + " " + monthsGenitive[julian.Month-1] + " по старому стилю"
+}
+
 func GenerateCalendarStats(reportDay *time.Time) string {
+	return GenerateCalendarStatsWith(reportDay, CalendarStatsOptions{})
+}
+
+func GenerateCalendarStatsWith(reportDay *time.Time, options CalendarStatsOptions) string {
 	firstLine := getFullDateString(reportDay)
+	if options.JulianDate {
+		firstLine = strings.TrimSuffix(firstLine, "*") + " (" + julianDateString(reportDay) + ")*"
+	}
 
 	year := time.Date(reportDay.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
 	infoDay := reportDay.YearDay()
@@ -276,31 +264,82 @@ func GenerateCalendarStats(reportDay *time.Time) string {
 		secondLine = "Завтра уже Новый Год!"
 	}
 
-	return firstLine + "\n" + secondLine + "\n"
+	stats := firstLine + "\n" + secondLine + "\n"
+	if options.ProductionCalendar {
+		stats += productionStats(reportDay)
+	}
+	return stats
 }
 
 type ReportCache struct {
 	sync.Mutex
+	source PageSource
 	year   int
 	month  time.Month
 	day    int
 	report *Report
+	stats  CalendarStatsOptions
+}
+
+func NewReportCache(source PageSource) *ReportCache {
+	return &ReportCache{source: source}
+}
+
+// SetCalendarStatsOptions changes the stats of the cached report, the report of today is generated again
+func (cache *ReportCache) SetCalendarStatsOptions(options CalendarStatsOptions) {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.stats = options
+	cache.report = nil
+}
+
+func (cache *ReportCache) GetTodaysReport() string {
+	return cache.GetTodaysReportAs(NewLegacyMarkdownRenderer())
+}
+
+func (cache *ReportCache) GetTodaysReportAs(renderer Renderer) string {
+	location, _ := time.LoadLocation(MoscowLocation)
+	log.Print(location)
+	now := time.Now().In(location)
+	report := cache.getCachedReport(&now)
+	return renderer.Render(&report)
+}
+
+func (cache *ReportCache) GetTodaysMessages(renderer *MessageRenderer, options RenderOptions) []string {
+	location, _ := time.LoadLocation(MoscowLocation)
+	now := time.Now().In(location)
+	report := cache.getCachedReport(&now)
+	return renderer.RenderMessages(&report, options)
+}
+
+// GetTodaysReportWithHistory adds the given number of random events of the day to the report
+func (cache *ReportCache) GetTodaysReportWithHistory(events int) string {
+	location, _ := time.LoadLocation(MoscowLocation)
+	now := time.Now().In(location)
+	report := cache.getCachedReport(&now)
+	random := rand.New(rand.NewSource(now.UnixNano()))
+	return NewLegacyMarkdownRenderer().RenderWith(&report, RenderOptions{HistoryEvents: events, Random: random})
 }
 
 func (cache *ReportCache) getCachedReport(date *time.Time) Report {
 	cache.Lock()
 	defer cache.Unlock()
 	year, month, day := date.Date()
-	if year == cache.year && month == cache.month && day == cache.day {
+	if cache.report != nil && year == cache.year && month == cache.month && day == cache.day {
 		return *cache.report
 	}
-	fullReport := getWikiReport(date)
-	report, err := Parse(fullReport)
+	page, err := cache.source.GetPage(getDateString(date))
+	if err != nil {
+		log.Print("Wikipedia is not respond: ", err)
+		return Report{}
+	}
+	report, err := Parse(page.Extract)
 	if err != nil {
 		log.Print("Error:", err)
 		return Report{}
 	}
-	report.SetCalendarInfo(date)
+	report.SetCalendarInfoWith(date, cache.stats)
+	report.AddMovableFeasts(date)
 	cache.report = &report
 	cache.year = year
 	cache.month = month